@@ -0,0 +1,71 @@
+// bloom 从 MySQL 重建帖子 ID 布隆过滤器
+//
+// 服务启动时会在过滤器不存在时自动构建；帖子被大量删除、预计容量调整前，
+// 或怀疑过滤器与数据库不一致时手动执行，重建期间查询照常使用旧过滤器。
+//
+// 用法:
+//
+//	go run ./cmd/bloom -conf ./config.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bluebell/internal/config"
+	"bluebell/internal/infrastructure/logger"
+	database "bluebell/internal/infrastructure/persistence/mysql"
+	redisrepo "bluebell/internal/infrastructure/persistence/redis"
+	postcache "bluebell/internal/infrastructure/persistence/redis/post"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	var confFile string
+	flag.StringVar(&confFile, "conf", "./config.yaml", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := config.Init(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init config failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(cfg, cfg.App.Mode); err != nil {
+		fmt.Fprintf(os.Stderr, "init logger failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	defer zap.L().Sync()
+
+	gormDB, err := database.Init(cfg)
+	if err != nil {
+		zap.L().Fatal("Init MySQL failed", zap.Error(err))
+	}
+	defer database.Close(gormDB)
+
+	rdb, err := redisrepo.Init(cfg)
+	if err != nil {
+		zap.L().Fatal("Init Redis failed", zap.Error(err))
+	}
+	defer redisrepo.Close(rdb)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	filter := postcache.NewBloomFilter(rdb, postcache.BloomOptions{
+		ExpectedItems:     cfg.Bloom.ExpectedPosts,
+		FalsePositiveRate: cfg.Bloom.FalsePositiveRate,
+		RebuildBatchSize:  cfg.Bloom.RebuildBatchSize,
+	})
+	n, err := filter.Rebuild(ctx, database.NewRepositories(gormDB).Post)
+	if err != nil {
+		// 失败时正式过滤器保持不变，临时位图已清理，可直接重试
+		zap.L().Error("rebuild post bloom filter failed", zap.Error(err))
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "rebuilt post bloom filter with %d posts\n", n)
+}
@@ -4,16 +4,27 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"time"
 
 	"bluebell/internal/config"
 	"bluebell/internal/di"
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
 	"bluebell/internal/http_server"
 	"bluebell/internal/infrastructure/es"
+	"bluebell/internal/infrastructure/eventbus"
+	"bluebell/internal/infrastructure/health"
+	"bluebell/internal/infrastructure/localcache"
+	"bluebell/internal/infrastructure/localsearch"
 	"bluebell/internal/infrastructure/logger"
 	"bluebell/internal/infrastructure/mq"
+	memorymq "bluebell/internal/infrastructure/mq/memory"
 	database "bluebell/internal/infrastructure/persistence/mysql"
+	"bluebell/internal/infrastructure/persistence/mysql/migrate"
 	redisrepo "bluebell/internal/infrastructure/persistence/redis"
+	postcache "bluebell/internal/infrastructure/persistence/redis/post"
 	"bluebell/internal/infrastructure/snowflake"
+	"bluebell/internal/infrastructure/tracing"
 	"bluebell/internal/infrastructure/translate"
 	"bluebell/internal/interfaces/http/handler"
 	"bluebell/internal/interfaces/http/router"
@@ -56,6 +67,19 @@ func main() {
 	}
 	defer zap.L().Sync()
 
+	// 初始化链路追踪，退出前导出剩余 Span
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		zap.L().Fatal("init tracing failed", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			zap.L().Error("shutdown tracing failed", zap.Error(err))
+		}
+	}()
+
 	// 初始化 snowflake
 	if err := snowflake.Init(cfg); err != nil {
 		zap.L().Fatal("init snowflake failed", zap.Error(err))
@@ -68,6 +92,27 @@ func main() {
 	}
 	defer database.Close(gormDB)
 
+	// 表结构迁移：开启 migrate_on_start 时执行未执行的版本（迁移锁保证多副本只执行一次），
+	// 否则只检查并提示，由发布流程执行 cmd/migrate up
+	migrator, err := migrate.New(gormDB, cfg.Mysql.MigrateLockTimeout)
+	if err != nil {
+		zap.L().Fatal("load migrations failed", zap.Error(err))
+	}
+	if cfg.Mysql.MigrateOnStart {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			zap.L().Fatal("migrate database failed", zap.Error(err))
+		}
+		for _, m := range applied {
+			zap.L().Info("migration applied", zap.Int64("version", m.Version), zap.String("name", m.Name))
+		}
+	} else if pending, err := migrator.Pending(context.Background()); err != nil {
+		zap.L().Warn("check pending migrations failed", zap.Error(err))
+	} else if len(pending) > 0 {
+		zap.L().Warn("database has pending migrations, run `migrate up` before serving traffic",
+			zap.Int64("latest", pending[len(pending)-1].Version), zap.Int("count", len(pending)))
+	}
+
 	// 4. 初始化 Redis
 	rdb, err := redisrepo.Init(cfg)
 	if err != nil {
@@ -75,6 +120,11 @@ func main() {
 	}
 	defer redisrepo.Close(rdb)
 
+	// 就绪检查：MySQL、Redis 为关键依赖，ES、MQ 为可选依赖，在下面初始化时登记
+	checker := health.NewCheckerFromConfig(cfg)
+	checker.Add(health.Check{Name: "mysql", Critical: true, Probe: health.MySQL(gormDB)})
+	checker.Add(health.Check{Name: "redis", Critical: true, Probe: health.Redis(rdb)})
+
 	// 初始化 Validator
 	if err := translate.InitTrans(); err != nil {
 		zap.L().Fatal("init validator trans failed", zap.Error(err))
@@ -87,90 +137,156 @@ func main() {
 	repositoriesUOW := database.NewRepositories(gormDB)
 	cacheRepos := redisrepo.NewRepositories(rdb)
 
+	// 帖子详情走 Cache-Aside：先查 Redis，未命中批量回源 MySQL 并写回
+	postDetails := postcache.NewCachedPostRepository(rdb, repositoriesUOW.Post, postcache.DetailOptions{
+		TTL:         cfg.Cache.PostTTL,
+		Jitter:      cfg.Cache.PostTTLJitter,
+		NegativeTTL: cfg.Cache.PostNegativeTTL,
+	})
+	repositoriesUOW.Post = postDetails
+
+	// 帖子 ID 布隆过滤器：查库前拦截不存在的帖子 ID，未构建时在后台从 MySQL 构建，构建完成前不拦截
+	postFilter := postcache.NewBloomFilter(rdb, postcache.BloomOptions{
+		ExpectedItems:     cfg.Bloom.ExpectedPosts,
+		FalsePositiveRate: cfg.Bloom.FalsePositiveRate,
+		RebuildBatchSize:  cfg.Bloom.RebuildBatchSize,
+	})
+	cacheRepos.PostFilter = postFilter
+	// 首次部署、参数变更或新帖子写入失败导致位图被删除后，在后台重建
+	go postFilter.RepairLoop(context.Background(), repositoriesUOW.Post)
+
+	// 进程内一级缓存：社区列表和热门帖子前几页先查本地 LRU，写入方经 Redis Pub/Sub 通知其他副本失效
+	localCaches := localcache.NewBus(rdb, cfg.LocalCache.Channel)
+	if err := localCaches.Start(context.Background()); err != nil {
+		zap.L().Fatal("subscribe local cache invalidation failed", zap.Error(err))
+	}
+	defer localCaches.Close()
+	communityList := cfg.LocalCache.Caches[localcache.NameCommunityList]
+	repositoriesUOW.Community = localcache.NewCommunityRepository(repositoriesUOW.Community,
+		localcache.New[[]*entity.Community](localcache.NameCommunityList, communityList.Size, communityList.TTL), localCaches)
+	hotFeed := cfg.LocalCache.Caches[localcache.NameHotFeed]
+	cacheRepos.PostCache = localcache.NewPostCacheRepository(cacheRepos.PostCache,
+		localcache.New[[]string](localcache.NameHotFeed, hotFeed.Size, hotFeed.TTL), localCaches)
+
 	// 启动 Gravity 热度分数定时刷新任务
 	cacheRepos.HotScoreRefresher.Start()
 	defer cacheRepos.HotScoreRefresher.Stop()
 
 	ctx := context.Background()
 
-	// ====== 基础设施层：ES / MQ ======
+	// ====== 基础设施层：搜索 / MQ ======
 
-	// 初始化 Elasticsearch 客户端
-	esClient, err := es.NewClient(cfg)
-	if err != nil {
-		zap.L().Error("init ES client failed", zap.Error(err))
-		esClient = nil
+	// 按配置选择搜索后端，查询端和索引写入端由同一实现提供
+	// 初始化失败时两者都为 nil：搜索接口返回错误，但不影响其他功能
+	var (
+		searchBackend domain.SearchBackend
+		searchIndexer domain.SearchIndexer
+	)
+	if cfg.Search != nil && cfg.Search.Driver == "embedded" {
+		index, err := localsearch.Open(cfg.Search.EmbeddedPath)
+		if err != nil {
+			zap.L().Fatal("open embedded search index failed", zap.Error(err))
+		}
+		defer func() {
+			if err := index.Close(); err != nil {
+				zap.L().Error("close embedded search index failed", zap.Error(err))
+			}
+		}()
+		searchBackend, searchIndexer = index, index
 	} else {
-		if err := esClient.CreatePostIndex(ctx); err != nil {
-			zap.L().Error("create ES post index failed", zap.Error(err))
+		esClient, err := es.NewClient(cfg)
+		if err != nil {
+			zap.L().Error("init ES client failed", zap.Error(err))
+			checker.Add(health.Check{Name: "elasticsearch", Probe: health.Unavailable(err)})
+		} else {
+			checker.Add(health.Check{Name: "elasticsearch", Probe: health.Elasticsearch(esClient)})
+			if err := esClient.CreatePostIndex(ctx); err != nil {
+				zap.L().Error("create ES post index failed", zap.Error(err))
+			}
+			if err := esClient.CreateRemarkIndex(ctx); err != nil {
+				zap.L().Error("create ES remark index failed", zap.Error(err))
+			}
+			searchBackend, searchIndexer = esClient, esClient
 		}
 	}
 
-	// ====== RabbitMQ 手动挡初始化 ======
-	// (1) 建立物理连接
-	conn, err := mq.Dial(cfg.RabbitMQ.URL)
-	if err != nil {
-		zap.L().Error("init MQ failed", zap.Error(err))
-		conn = nil
-	}
+	// ====== 消息队列初始化 ======
+	// 消费失败的退避重试策略，同时决定需要声明哪些重试队列
+	retryPolicy := mq.RetryPolicyFromConfig(cfg)
 
-	var publisher *mq.Publisher
-	if conn != nil {
-		// (2) 装修资源：临时信道声明 Exchange 和 Queue
-		setupCh, err := conn.Channel()
-		if err != nil {
-			zap.L().Fatal("create setup channel failed", zap.Error(err))
-		}
-		if err := mq.SetupResources(setupCh); err != nil {
-			zap.L().Fatal("setup rabbitmq resources failed", zap.Error(err))
+	// (1) 按配置选择 Broker 实现，得到与 Broker 无关的发布者和订阅者
+	var (
+		publisher  domain.Publisher
+		subscriber domain.Subscriber
+	)
+	switch cfg.RabbitMQ.Driver {
+	case "memory":
+		// 进程内 Broker：无需 RabbitMQ，消息不落盘，仅用于开发和测试
+		broker := memorymq.NewBroker(retryPolicy.MaxAttempts, retryPolicy.Delay)
+		for _, t := range mq.Topologies() {
+			broker.Bind(t.Exchange, t.RoutingKey, t.Queue)
 		}
-		setupCh.Close()
+		defer broker.Close()
+		publisher, subscriber = broker, broker
+		zap.L().Info("using in-memory message broker")
+	default:
+		// 建立连接并声明交换机和队列；首次连接失败或断线后由连接管理器在后台重连并重新声明，
+		// 期间发件箱消息保持待投递，消费者等待连接恢复，健康检查报告 rabbitmq 不可用
+		mqConn := mq.NewConnectionManagerFromConfig(cfg, retryPolicy)
+		defer mqConn.Close()
+		checker.Add(health.Check{Name: "rabbitmq", Probe: health.RabbitMQ(mqConn)})
 
-		// (3) 发布信道：给生产者用
-		pubCh, err := conn.Channel()
-		if err != nil {
-			zap.L().Fatal("create publish channel failed", zap.Error(err))
-		}
-		defer pubCh.Close()
-		publisher = mq.NewPublisher(pubCh)
+		// 发布者：给发件箱中继用（开启 Publisher Confirms）
+		confirmPublisher := mq.NewConfirmPublisher(mqConn)
+		defer confirmPublisher.Close()
+		publisher, subscriber = confirmPublisher, mq.NewSubscriber(mqConn, retryPolicy)
+	}
+	if publisher != nil {
+		publisher, subscriber = mq.InstrumentPublisher(publisher), mq.InstrumentSubscriber(subscriber)
+	}
+
+	// (2) 发件箱中继：把 outbox 表中的消息投递到 Broker
+	var outboxRelay *mq.OutboxRelay
+	if publisher != nil {
+		outboxRelay = mq.NewOutboxRelay(publisher, repositoriesUOW.Outbox, repositoriesUOW.Tx,
+			cfg.Outbox.PollInterval, cfg.Outbox.BatchSize, cfg.Outbox.MaxAttempts)
+		outboxRelay.Start()
 	}
+	// 定期删除超过保留期的已投递消息
+	outboxCleaner := mq.NewOutboxCleaner(repositoriesUOW.Outbox,
+		time.Duration(cfg.Outbox.RetentionDays)*24*time.Hour, cfg.Outbox.CleanupInterval)
+	outboxCleaner.Start()
+
+	// 领域事件总线：服务发布事件，需要跨进程处理的事件经桥接写入发件箱
+	eventBus := eventbus.New()
+	mq.RegisterOutboxBridge(eventBus, repositoriesUOW.Outbox)
+	// 帖子编辑、隐藏、恢复、删除时清除详情缓存
+	postDetails.RegisterInvalidation(eventBus)
 
 	// 2) 业务逻辑层：创建 Service 实例
-	services := di.NewServices(repositoriesUOW, cacheRepos, publisher, esClient, cfg)
+	services := di.NewServices(repositoriesUOW, cacheRepos, searchBackend, eventBus, cfg)
 
 	// 3) 表现层：创建 Handler 实例
 	handlerProvider := handler.NewProvider(
 		services.User,
 		services.Post,
 		services.Community,
-		publisher,
 	)
 
-	// 4) 创建并启动 MQ 消费者
-	if conn != nil {
-		// 消费信道：给投票消费者用
-		voteCh, err := conn.Channel()
-		if err != nil {
-			zap.L().Fatal("create vote consumer channel failed", zap.Error(err))
-		}
-		defer voteCh.Close()
-		voteConsumer := mq.NewVoteConsumer(voteCh, repositoriesUOW.Vote, rdb)
+	// 4) 创建并启动 MQ 消费者，每个消费者使用独立信道，断线后自动恢复
+	if subscriber != nil {
+		voteConsumer := mq.NewVoteConsumer(subscriber, repositoriesUOW.Vote, rdb,
+			cfg.RabbitMQ.VoteBatchSize, cfg.RabbitMQ.VoteFlushInterval)
 		go func() {
 			if err := voteConsumer.Start(ctx); err != nil {
 				zap.L().Error("vote consumer exited", zap.Error(err))
 			}
 		}()
 
-		if esClient != nil {
-			// 消费信道：给搜索消费者用
-			searchCh, err := conn.Channel()
-			if err != nil {
-				zap.L().Fatal("create search consumer channel failed", zap.Error(err))
-			}
-			defer searchCh.Close()
-			esConsumer := mq.NewSyncConsumer(searchCh, esClient)
+		if searchIndexer != nil {
+			syncConsumer := mq.NewSyncConsumer(subscriber, searchIndexer)
 			go func() {
-				if err := esConsumer.Start(ctx); err != nil {
+				if err := syncConsumer.Start(ctx); err != nil {
 					zap.L().Error("sync consumer exited", zap.Error(err))
 				}
 			}()
@@ -178,7 +294,7 @@ func main() {
 	}
 
 	// 5) 路由层：初始化路由，注入 Handler
-	r, err := router.NewRouter(cfg.App.Mode, handlerProvider, cfg, cacheRepos.TokenCache)
+	r, err := router.NewRouter(cfg.App.Mode, handlerProvider, cfg, cacheRepos.TokenCache, cacheRepos.IdempotencyCache, checker)
 	if err != nil {
 		zap.L().Fatal("init router failed", zap.Error(err))
 	}
@@ -186,8 +302,9 @@ func main() {
 	// 6. 启动 HTTP 服务（含优雅关机）
 	http_server.Run(r, cfg.App.Port)
 
-	// HTTP 服务已关闭，关闭 MQ 连接
-	if conn != nil {
-		conn.Close()
+	// HTTP 服务已关闭，停止发件箱中继和清理任务；MQ 连接由 defer 关闭
+	if outboxRelay != nil {
+		outboxRelay.Stop()
 	}
+	outboxCleaner.Stop()
 }
@@ -0,0 +1,34 @@
+// config 配置文件工具
+//
+// 用法:
+//
+//	go run ./cmd/config check -conf ./config.yaml
+//
+// check 按服务启动时相同的规则加载配置（默认值、环境变量、*_FILE 密钥文件），
+// 列出所有校验问题；配置有效时退出码为 0，否则为 1，可用于部署前检查
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"bluebell/internal/config"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: config check [-conf ./config.yaml]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	confFile := fs.String("conf", "./config.yaml", "配置文件路径")
+	_ = fs.Parse(os.Args[2:])
+
+	if _, err := config.Load(*confFile); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *confFile, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: OK\n", *confFile)
+}
@@ -7,12 +7,15 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"bluebell/internal/config"
 	"bluebell/internal/infrastructure/es"
+	"bluebell/internal/infrastructure/health"
 	"bluebell/internal/infrastructure/logger"
 	"bluebell/internal/infrastructure/mq"
 	"bluebell/internal/infrastructure/snowflake"
+	"bluebell/internal/infrastructure/tracing"
 
 	"go.uber.org/zap"
 )
@@ -38,6 +41,19 @@ func main() {
 	// 程序退出前将缓冲区日志刷入磁盘
 	defer zap.L().Sync()
 
+	// 初始化链路追踪，退出前导出剩余 Span
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		zap.L().Fatal("init tracing failed", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			zap.L().Error("shutdown tracing failed", zap.Error(err))
+		}
+	}()
+
 	// 4. 初始化分布式 ID 生成器 Snowflake
 	if err := snowflake.Init(cfg); err != nil {
 		zap.L().Fatal("init snowflake failed", zap.Error(err))
@@ -54,39 +70,29 @@ func main() {
 		zap.L().Fatal("init ES client failed", zap.Error(err))
 	}
 
-	// 8. 建立 RabbitMQ 物理连接 (TCP)
-	conn, err := mq.Dial(cfg.RabbitMQ.URL)
-	if err != nil {
-		zap.L().Fatal("init MQ failed", zap.Error(err))
-	}
-	// 程序退出前关闭物理连接
-	defer conn.Close()
+	// 8. 构建消费失败的退避重试策略，同时决定需要声明哪些重试队列
+	retryPolicy := mq.RetryPolicyFromConfig(cfg)
 
-	// 9. 装修资源：声明必要的交换机 (Exchange) 和队列 (Queue)
-	// 使用临时信道进行声明，确保消费者启动前队列已存在
-	setupCh, err := conn.Channel()
-	if err != nil {
-		zap.L().Fatal("create setup channel failed", zap.Error(err))
-	}
-	if err := mq.SetupResources(setupCh); err != nil {
-		zap.L().Fatal("setup rabbitmq resources failed", zap.Error(err))
-	}
-	// 声明任务完成后立即关闭临时信道
-	setupCh.Close()
+	// 9. 建立 RabbitMQ 连接并声明交换机 (Exchange) 和队列 (Queue)
+	// 首次连接失败或断线后由连接管理器在后台重连并重新声明，确保消费者恢复前队列已存在
+	mqConn := mq.NewConnectionManagerFromConfig(cfg, retryPolicy)
+	// 程序退出前关闭连接
+	defer mqConn.Close()
 
-	// 10. 创建专门用于消息消费的信道
+	// 10. 初始化搜索同步消费者实例
+	// 注入 RabbitMQ 订阅者和 ES 客户端，订阅者为消费者打开专用的消费信道，
 	// 遵循“发布/消费信道分离”的最佳实践
-	ch, err := conn.Channel()
-	if err != nil {
-		zap.L().Fatal("create sync consumer channel failed", zap.Error(err))
+	consumer := mq.NewSyncConsumer(mq.NewSubscriber(mqConn, retryPolicy), esClient)
+	
+	// 11. 健康检查端点：本进程的职责就是消费并写入 ES，两者均为关键依赖
+	checker := health.NewCheckerFromConfig(cfg)
+	checker.Add(health.Check{Name: "rabbitmq", Critical: true, Probe: health.RabbitMQ(mqConn)})
+	checker.Add(health.Check{Name: "elasticsearch", Critical: true, Probe: health.Elasticsearch(esClient)})
+	if cfg.Health != nil && cfg.Health.SyncConsumerPort > 0 {
+		healthSrv := health.Serve(cfg.Health.SyncConsumerPort, checker)
+		defer healthSrv.Shutdown(context.Background())
 	}
-	// 程序退出前关闭消费信道
-	defer ch.Close()
 
-	// 11. 初始化搜索同步消费者实例
-	// 注入信道和 ES 客户端
-	consumer := mq.NewSyncConsumer(ch, esClient)
-	
 	zap.L().Info("Starting Sync Consumer (ES)...")
 	// 12. 在独立的协程中启动消费者监听
 	go func() {
@@ -102,5 +108,5 @@ func main() {
 	<-quit
 
 	zap.L().Info("Shutting down Sync Consumer...")
-	// 此处函数结束，触发 defer 执行，依次关闭信道、连接和上下文
+	// 此处函数结束，触发 defer 执行，依次关闭连接和上下文
 }
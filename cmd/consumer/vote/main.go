@@ -7,13 +7,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"bluebell/internal/config"
+	"bluebell/internal/infrastructure/health"
 	"bluebell/internal/infrastructure/logger"
 	"bluebell/internal/infrastructure/mq"
 	database "bluebell/internal/infrastructure/persistence/mysql"
 	redisrepo "bluebell/internal/infrastructure/persistence/redis"
 	"bluebell/internal/infrastructure/snowflake"
+	"bluebell/internal/infrastructure/tracing"
 
 	"go.uber.org/zap"
 )
@@ -35,6 +38,19 @@ func main() {
 	}
 	defer zap.L().Sync()
 
+	// 初始化链路追踪，退出前导出剩余 Span
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		zap.L().Fatal("init tracing failed", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			zap.L().Error("shutdown tracing failed", zap.Error(err))
+		}
+	}()
+
 	if err := snowflake.Init(cfg); err != nil {
 		zap.L().Fatal("init snowflake failed", zap.Error(err))
 	}
@@ -54,32 +70,27 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	conn, err := mq.Dial(cfg.RabbitMQ.URL)
-	if err != nil {
-		zap.L().Fatal("init MQ failed", zap.Error(err))
-	}
-	defer conn.Close()
+	retryPolicy := mq.RetryPolicyFromConfig(cfg)
 
-	// Setup resources
-	setupCh, err := conn.Channel()
-	if err != nil {
-		zap.L().Fatal("create setup channel failed", zap.Error(err))
-	}
-	if err := mq.SetupResources(setupCh); err != nil {
-		zap.L().Fatal("setup rabbitmq resources failed", zap.Error(err))
-	}
-	setupCh.Close()
+	// Connect and setup resources, reconnecting in the background if the broker is down at startup or restarts
+	mqConn := mq.NewConnectionManagerFromConfig(cfg, retryPolicy)
+	defer mqConn.Close()
 
 	// Start Vote Consumer
-	ch, err := conn.Channel()
-	if err != nil {
-		zap.L().Fatal("create vote consumer channel failed", zap.Error(err))
-	}
-	defer ch.Close()
-
 	repositoriesUOW := database.NewRepositories(gormDB)
-	consumer := mq.NewVoteConsumer(ch, repositoriesUOW.Vote, rdb)
+	consumer := mq.NewVoteConsumer(mq.NewSubscriber(mqConn, retryPolicy), repositoriesUOW.Vote, rdb,
+		cfg.RabbitMQ.VoteBatchSize, cfg.RabbitMQ.VoteFlushInterval)
 	
+	// 健康检查端点：本进程从 MQ 消费投票并写入 MySQL 和 Redis，三者均为关键依赖
+	checker := health.NewCheckerFromConfig(cfg)
+	checker.Add(health.Check{Name: "mysql", Critical: true, Probe: health.MySQL(gormDB)})
+	checker.Add(health.Check{Name: "redis", Critical: true, Probe: health.Redis(rdb)})
+	checker.Add(health.Check{Name: "rabbitmq", Critical: true, Probe: health.RabbitMQ(mqConn)})
+	if cfg.Health != nil && cfg.Health.VoteConsumerPort > 0 {
+		healthSrv := health.Serve(cfg.Health.VoteConsumerPort, checker)
+		defer healthSrv.Shutdown(context.Background())
+	}
+
 	zap.L().Info("Starting Vote Consumer...")
 	go func() {
 		if err := consumer.Start(ctx); err != nil {
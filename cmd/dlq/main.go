@@ -0,0 +1,103 @@
+// dlq 死信队列运维工具
+//
+// 用法:
+//
+//	go run ./cmd/dlq -consumer vote -action inspect -limit 20
+//	go run ./cmd/dlq -consumer search -action replay -limit 100
+//	go run ./cmd/dlq -consumer vote -action purge
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bluebell/internal/config"
+	"bluebell/internal/infrastructure/mq"
+)
+
+func main() {
+	var (
+		confFile string
+		consumer string
+		action   string
+		limit    int
+	)
+	flag.StringVar(&confFile, "conf", "./config.yaml", "配置文件路径")
+	flag.StringVar(&consumer, "consumer", "", "消费者名称: vote | search")
+	flag.StringVar(&action, "action", "inspect", "操作: inspect | replay | purge")
+	flag.IntVar(&limit, "limit", 10, "inspect / replay 处理的最大消息数")
+	flag.Parse()
+
+	t, ok := mq.LookupTopology(consumer)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown consumer %q, expected vote or search\n", consumer)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Init(confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init config failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	policy := mq.RetryPolicyFromConfig(cfg)
+
+	conn, err := mq.Dial(cfg.RabbitMQ.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init MQ failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "create channel failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	defer ch.Close()
+
+	// 确保死信队列存在，避免在全新环境中操作报 NOT_FOUND
+	if err := mq.SetupResources(ch, policy); err != nil {
+		fmt.Fprintf(os.Stderr, "setup rabbitmq resources failed, err:%v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	switch action {
+	case "inspect":
+		letters, err := mq.InspectDeadLetters(ch, t, limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "inspect failed, err:%v\n", err)
+			os.Exit(1)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		for _, l := range letters {
+			_ = enc.Encode(l)
+		}
+		fmt.Fprintf(os.Stderr, "%d dead letters shown from %s\n", len(letters), t.DLQ)
+	case "replay":
+		n, err := mq.ReplayDeadLetters(ctx, ch, t, limit)
+		fmt.Fprintf(os.Stderr, "%d dead letters replayed from %s to %s\n", n, t.DLQ, t.Exchange)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay failed, err:%v\n", err)
+			os.Exit(1)
+		}
+	case "purge":
+		n, err := mq.PurgeDeadLetters(ch, t)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "purge failed, err:%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "%d dead letters purged from %s\n", n, t.DLQ)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown action %q, expected inspect, replay or purge\n", action)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,120 @@
+// migrate MySQL 表结构迁移与初始数据
+//
+// 用法:
+//
+//	go run ./cmd/migrate up     -conf ./config.yaml   执行所有未执行的迁移
+//	go run ./cmd/migrate down   -steps 1              回滚最近的 steps 个迁移
+//	go run ./cmd/migrate status                       列出各版本的执行状态
+//	go run ./cmd/migrate seed                         写入初始数据（默认社区、管理员），可重复执行
+//
+// 管理员密码通过 SEED_ADMIN_PASSWORD 或 SEED_ADMIN_PASSWORD_FILE 提供，未提供时 seed 不创建管理员
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bluebell/internal/config"
+	"bluebell/internal/infrastructure/logger"
+	database "bluebell/internal/infrastructure/persistence/mysql"
+	"bluebell/internal/infrastructure/persistence/mysql/migrate"
+	"bluebell/internal/infrastructure/snowflake"
+
+	"go.uber.org/zap"
+)
+
+const usage = "usage: migrate up|down|status|seed [-conf ./config.yaml] [-steps 1]"
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd := os.Args[1]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	confFile := fs.String("conf", "./config.yaml", "配置文件路径")
+	steps := fs.Int("steps", 1, "down 回滚的版本数")
+	_ = fs.Parse(os.Args[2:])
+
+	cfg, err := config.Init(*confFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init config failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(cfg, cfg.App.Mode); err != nil {
+		fmt.Fprintf(os.Stderr, "init logger failed, err:%v\n", err)
+		os.Exit(1)
+	}
+	defer zap.L().Sync()
+
+	gormDB, err := database.Init(cfg)
+	if err != nil {
+		zap.L().Fatal("Init MySQL failed", zap.Error(err))
+	}
+	defer database.Close(gormDB)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	m, err := migrate.New(gormDB, cfg.Mysql.MigrateLockTimeout)
+	if err != nil {
+		zap.L().Fatal("load migrations failed", zap.Error(err))
+	}
+
+	switch cmd {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied %d_%s\n", mig.Version, mig.Name)
+		}
+		exitOnError(err)
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(ctx, *steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %d_%s\n", mig.Version, mig.Name)
+		}
+		exitOnError(err)
+	case "status":
+		status, err := m.Status(ctx)
+		exitOnError(err)
+		for _, s := range status {
+			state := "pending"
+			switch {
+			case s.Dirty:
+				state = "DIRTY"
+			case s.Applied:
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	case "seed":
+		// 管理员的 user_id 由 snowflake 生成
+		if err := snowflake.Init(cfg); err != nil {
+			zap.L().Fatal("init snowflake failed", zap.Error(err))
+		}
+		exitOnError(database.Seed(ctx, gormDB, database.SeedOptions{
+			DefaultCommunities: cfg.Seed.DefaultCommunities,
+			AdminUsername:      cfg.Seed.AdminUsername,
+			AdminPassword:      cfg.Seed.AdminPassword,
+		}))
+		fmt.Println("seed done")
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
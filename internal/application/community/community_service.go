@@ -13,6 +13,9 @@ import (
 	// 错误处理
 	"bluebell/internal/domain/entity"
 
+	// 日志（携带请求 ID、用户 ID 等上下文字段）
+	"bluebell/internal/infrastructure/logger"
+
 	"context"
 	"strconv"
 
@@ -46,7 +49,7 @@ func toResponse(c *entity.Community) *communityResp.Response {
 func (s *communityServiceStruct) GetCommunityList(ctx context.Context) ([]*communityResp.Response, error) {
 	data, err := s.communityRepo.GetCommunityList(ctx)
 	if err != nil {
-		zap.L().Error("communityRepo.GetCommunityList failed", zap.Error(err))
+		logger.WithContext(ctx).Error("communityRepo.GetCommunityList failed", zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
@@ -61,7 +64,7 @@ func (s *communityServiceStruct) GetCommunityList(ctx context.Context) ([]*commu
 func (s *communityServiceStruct) GetCommunityDetail(ctx context.Context, id int64) (*communityResp.Response, error) {
 	data, err := s.communityRepo.GetCommunityDetailByID(ctx, id)
 	if err != nil {
-		zap.L().Error("communityRepo.GetCommunityDetailByID failed",
+		logger.WithContext(ctx).Error("communityRepo.GetCommunityDetailByID failed",
 			zap.Int64("community_id", id),
 			zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
@@ -79,7 +82,7 @@ func (s *communityServiceStruct) CreateCommunity(ctx context.Context, name, intr
 	// 1. 校验用户角色是否为管理员
 	user, err := s.userRepo.CheckUserExistsByID(ctx, userID)
 	if err != nil {
-		zap.L().Error("userRepo.CheckUserExistsByID failed",
+		logger.WithContext(ctx).Error("userRepo.CheckUserExistsByID failed",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
@@ -94,7 +97,7 @@ func (s *communityServiceStruct) CreateCommunity(ctx context.Context, name, intr
 		Introduction:  introduction,
 	}
 	if err := s.communityRepo.CreateCommunity(ctx, community); err != nil {
-		zap.L().Error("communityRepo.CreateCommunity failed",
+		logger.WithContext(ctx).Error("communityRepo.CreateCommunity failed",
 			zap.String("community_name", name),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
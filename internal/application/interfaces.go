@@ -3,13 +3,13 @@ package application
 import (
 	// DTO
 	postreq "bluebell/internal/interfaces/http/dto/request/post"
+	searchreq "bluebell/internal/interfaces/http/dto/request/search"
 	userreq "bluebell/internal/interfaces/http/dto/request/user"
 	votereq "bluebell/internal/interfaces/http/dto/request/vote"
 	communityResp "bluebell/internal/interfaces/http/dto/response/community"
 	postResp "bluebell/internal/interfaces/http/dto/response/post"
 	voteresp "bluebell/internal/interfaces/http/dto/response/vote"
 	"bluebell/internal/domain/entity"
-	"bluebell/internal/infrastructure/es"
 
 	"context"
 )
@@ -49,11 +49,15 @@ type PostService interface {
 	VoteForPost(ctx context.Context, userID int64, p *postreq.VoteRequest) error
 	//发表评论
 	RemarkPost(ctx context.Context, req *postreq.RemarkRequest, userID int64) (remarkID uint, err error)
+	// GetRelatedPosts 获取与帖子内容相近的帖子
+	GetRelatedPosts(ctx context.Context, postID int64, p *postreq.RelatedPostsRequest) ([]*postResp.DetailResponse, error)
 	// GetPostRemarks 获取帖子评论列表
 	GetPostRemarks(ctx context.Context, postID int64) ([]*postResp.RemarkDetail, error)
 
 	// SearchPosts 全文搜索帖子
-	SearchPosts(ctx context.Context, keyword string, page, pageSize int) (*es.SearchResponse, error)
+	SearchPosts(ctx context.Context, req *searchreq.SearchRequest) (*postResp.SearchResponse, error)
+	// SuggestPosts 搜索框输入联想：帖子标题和社区名称
+	SuggestPosts(ctx context.Context, req *searchreq.SuggestRequest) (*postResp.SuggestResponse, error)
 }
 
 // ========== User Service 接口 ==========
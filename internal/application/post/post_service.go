@@ -9,19 +9,26 @@ import (
 
 	// DTO
 	postreq "bluebell/internal/interfaces/http/dto/request/post"
+	searchreq "bluebell/internal/interfaces/http/dto/request/search"
 	postResp "bluebell/internal/interfaces/http/dto/response/post"
 
 	// 基础设施
-	"bluebell/internal/infrastructure/es"
 	"bluebell/internal/infrastructure/snowflake"
-	"bluebell/internal/infrastructure/mq"
 
 	// 错误处理
 	"bluebell/internal/domain/entity"
 
+	// 日志（携带请求 ID、用户 ID 等上下文字段）
+	"bluebell/internal/infrastructure/logger"
+
+	// 领域事件
+	"bluebell/internal/domain/event"
+
 	"context"
 	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -30,29 +37,74 @@ import (
 type postServiceStruct struct {
 	postRepo   domain.PostRepository
 	postCache  domain.PostCacheRepository
+	postFilter domain.PostFilter
 	voteRepo   domain.VoteRepository
 	remarkRepo domain.RemarkRepository
-	publisher *mq.Publisher
-	esClient   *es.Client
+	userRepo   domain.UserRepository
+	commRepo   domain.CommunityRepository
+	events     domain.EventPublisher
+	txManager  domain.TransactionManager
+	search     domain.SearchBackend
 }
 
 // NewPostService 创建帖子服务实例
 func NewPostService(
 	postRepo domain.PostRepository,
 	postCache domain.PostCacheRepository,
+	postFilter domain.PostFilter,
 	voteRepo domain.VoteRepository,
 	remarkRepo domain.RemarkRepository,
-	publisher *mq.Publisher,
-	esClient *es.Client,
+	userRepo domain.UserRepository,
+	commRepo domain.CommunityRepository,
+	events domain.EventPublisher,
+	txManager domain.TransactionManager,
+	search domain.SearchBackend,
 ) application.PostService {
 	return &postServiceStruct{
 		postRepo:   postRepo,
 		postCache:  postCache,
+		postFilter: postFilter,
 		voteRepo:   voteRepo,
 		remarkRepo: remarkRepo,
-		publisher:  publisher,
-		esClient:   esClient,
+		userRepo:   userRepo,
+		commRepo:   commRepo,
+		events:     events,
+		txManager:  txManager,
+		search:     search,
+	}
+}
+
+// postSnapshot 构造帖子事件快照
+// 搜索索引只通过帖子生命周期事件同步（见 mq.OutboxBridge），HTTP、CLI 等所有调用方走同一条路径
+func postSnapshot(p *entity.Post) event.PostSnapshot {
+	postID, _ := strconv.ParseInt(p.PostID, 10, 64)
+	return event.PostSnapshot{
+		PostID:      postID,
+		AuthorID:    p.AuthorID,
+		CommunityID: p.CommunityID,
+		Title:       p.PostTitle,
+		Content:     p.Content,
+		Status:      p.Status,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// postMayExist 查库前拦截一定不存在的帖子 ID（非正数或被布隆过滤器排除）
+// 过滤器出错时放行，由数据库给出结果
+func (s *postServiceStruct) postMayExist(ctx context.Context, postID int64) bool {
+	if postID <= 0 {
+		return false
 	}
+	if s.postFilter == nil {
+		return true
+	}
+	ok, err := s.postFilter.MightExist(ctx, postID)
+	if err != nil {
+		logger.WithContext(ctx).Warn("postFilter.MightExist failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+	}
+	return ok
 }
 
 // CreatePost 创建帖子
@@ -73,9 +125,26 @@ func (s *postServiceStruct) CreatePost(ctx context.Context, p *postreq.CreatePos
 		return "", entity.ErrInvalidParam
 	}
 
-	err = s.postRepo.CreatePost(ctx, post)
+	// 先写入布隆过滤器再落库：落库失败只多出一个误判位，反过来则新帖子会被过滤器拦截。
+	// 写入失败不影响发帖，过滤器会放行查询直到重建完成
+	if s.postFilter != nil {
+		if err := s.postFilter.Add(ctx, postIDInt); err != nil {
+			logger.WithContext(ctx).Error("postFilter.Add failed, filter will be rebuilt",
+				zap.Int64("post_id", postIDInt),
+				zap.Error(err))
+		}
+	}
+
+	// 帖子与 PostCreated 事件在同一个事务中处理，事件处理器写入的发件箱消息随帖子一同提交，
+	// 保证不会出现“帖子已保存但索引消息丢失”
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.postRepo.CreatePost(ctx, post); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, event.PostCreated{PostSnapshot: postSnapshot(post)})
+	})
 	if err != nil {
-		zap.L().Error("postRepo.CreatePost failed",
+		logger.WithContext(ctx).Error("postRepo.CreatePost failed",
 			zap.Int64("post_id", postIDInt),
 			zap.Error(err))
 		return "", entity.Wrap(entity.ErrServerBusy, err)
@@ -84,7 +153,7 @@ func (s *postServiceStruct) CreatePost(ctx context.Context, p *postreq.CreatePos
 	// 同步到 Redis
 	err = s.postCache.CreatePost(ctx, postIDInt, p.CommunityID)
 	if err != nil {
-		zap.L().Error("postCache.CreatePost failed",
+		logger.WithContext(ctx).Error("postCache.CreatePost failed",
 			zap.Int64("post_id", postIDInt),
 			zap.Error(err))
 	}
@@ -94,9 +163,13 @@ func (s *postServiceStruct) CreatePost(ctx context.Context, p *postreq.CreatePos
 
 // GetPostByID 查询单个帖子详情
 func (s *postServiceStruct) GetPostByID(ctx context.Context, pid int64) (data *postResp.DetailResponse, err error) {
+	if !s.postMayExist(ctx, pid) {
+		return nil, entity.ErrNotFound
+	}
+
 	post, err := s.postRepo.GetPostByID(ctx, pid)
 	if err != nil {
-		zap.L().Error("postRepo.GetPostByID failed",
+		logger.WithContext(ctx).Error("postRepo.GetPostByID failed",
 			zap.Int64("post_id", pid),
 			zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
@@ -107,14 +180,14 @@ func (s *postServiceStruct) GetPostByID(ctx context.Context, pid int64) (data *p
 	}
 
 	if post.Author == nil || post.Author.UserID == 0 {
-		zap.L().Warn("author not found for post",
+		logger.WithContext(ctx).Warn("author not found for post",
 			zap.Int64("post_id", pid),
 			zap.Int64("author_id", post.AuthorID))
 		return nil, entity.ErrNotFound
 	}
 
 	if post.Community == nil || post.Community.ID == 0 {
-		zap.L().Warn("community not found for post",
+		logger.WithContext(ctx).Warn("community not found for post",
 			zap.Int64("post_id", pid),
 			zap.Int64("community_id", post.CommunityID))
 		return nil, entity.ErrNotFound
@@ -138,31 +211,31 @@ func (s *postServiceStruct) GetPostByID(ctx context.Context, pid int64) (data *p
 func (s *postServiceStruct) GetPostList(ctx context.Context, p *postreq.PostListRequest) (data []*postResp.DetailResponse, err error) {
 	ids, err := s.postCache.GetPostIDsInOrder(ctx, p.Order, p.Page, p.Size)
 	if err != nil {
-		zap.L().Error("postCache.GetPostIDsInOrder failed",
+		logger.WithContext(ctx).Error("postCache.GetPostIDsInOrder failed",
 			zap.String("order", p.Order),
 			zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
 	if len(ids) == 0 {
-		zap.L().Warn("postCache.GetPostIDsInOrder() return 0 data")
+		logger.WithContext(ctx).Warn("postCache.GetPostIDsInOrder() return 0 data")
 		data = make([]*postResp.DetailResponse, 0)
 		return
 	}
 
-	zap.L().Debug("GetPostList", zap.Any("ids", ids))
+	logger.WithContext(ctx).Debug("GetPostList", zap.Any("ids", ids))
 
 	posts, err := s.postRepo.GetPostListByIDsWithPreload(ctx, ids)
 	if err != nil {
-		zap.L().Error("postRepo.GetPostListByIDsWithPreload failed", zap.Error(err))
+		logger.WithContext(ctx).Error("postRepo.GetPostListByIDsWithPreload failed", zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
-	zap.L().Debug("GetPostListByIDsWithPreload", zap.Any("posts", posts))
+	logger.WithContext(ctx).Debug("GetPostListByIDsWithPreload", zap.Any("posts", posts))
 
 	voteData, err := s.postCache.GetPostsVoteData(ctx, ids)
 	if err != nil {
-		zap.L().Error("postCache.GetPostsVoteData failed", zap.Error(err))
+		logger.WithContext(ctx).Error("postCache.GetPostsVoteData failed", zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
@@ -171,7 +244,7 @@ func (s *postServiceStruct) GetPostList(ctx context.Context, p *postreq.PostList
 		if post.Author != nil {
 			authorName = post.Author.UserName
 		} else {
-			zap.L().Error("author not preloaded for post",
+			logger.WithContext(ctx).Error("author not preloaded for post",
 				zap.String("post_id", post.PostID),
 				zap.Int64("author_id", post.AuthorID))
 		}
@@ -197,7 +270,7 @@ func (s *postServiceStruct) GetPostList(ctx context.Context, p *postreq.PostList
 func (s *postServiceStruct) GetCommunityPostList(ctx context.Context, p *postreq.PostListRequest) (data []*postResp.DetailResponse, err error) {
 	ids, err := s.postCache.GetCommunityPostIDsInOrder(ctx, p.CommunityID, p.Order, p.Page, p.Size)
 	if err != nil {
-		zap.L().Error("postCache.GetCommunityPostIDsInOrder failed",
+		logger.WithContext(ctx).Error("postCache.GetCommunityPostIDsInOrder failed",
 			zap.Int64("community_id", p.CommunityID),
 			zap.String("order", p.Order),
 			zap.Error(err))
@@ -205,23 +278,23 @@ func (s *postServiceStruct) GetCommunityPostList(ctx context.Context, p *postreq
 	}
 
 	if len(ids) == 0 {
-		zap.L().Info("GetCommunityPostList: no posts found",
+		logger.WithContext(ctx).Info("GetCommunityPostList: no posts found",
 			zap.Int64("community_id", p.CommunityID))
 		data = make([]*postResp.DetailResponse, 0)
 		return data, nil
 	}
 
-	zap.L().Debug("GetCommunityPostList", zap.Any("ids", ids))
+	logger.WithContext(ctx).Debug("GetCommunityPostList", zap.Any("ids", ids))
 
 	posts, err := s.postRepo.GetPostListByIDsWithPreload(ctx, ids)
 	if err != nil {
-		zap.L().Error("postRepo.GetPostListByIDsWithPreload failed", zap.Error(err))
+		logger.WithContext(ctx).Error("postRepo.GetPostListByIDsWithPreload failed", zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
 	voteData, err := s.postCache.GetPostsVoteData(ctx, ids)
 	if err != nil {
-		zap.L().Error("postCache.GetPostsVoteData failed", zap.Error(err))
+		logger.WithContext(ctx).Error("postCache.GetPostsVoteData failed", zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
 	}
 
@@ -231,7 +304,7 @@ func (s *postServiceStruct) GetCommunityPostList(ctx context.Context, p *postreq
 		if post.Author != nil {
 			authorName = post.Author.UserName
 		} else {
-			zap.L().Error("author not preloaded for post",
+			logger.WithContext(ctx).Error("author not preloaded for post",
 				zap.String("post_id", post.PostID),
 				zap.Int64("author_id", post.AuthorID))
 		}
@@ -257,7 +330,7 @@ func (s *postServiceStruct) GetCommunityPostList(ctx context.Context, p *postreq
 func (s *postServiceStruct) DeletePost(ctx context.Context, postID int64, userID int64) error {
 	post, err := s.postRepo.GetPostByID(ctx, postID)
 	if err != nil {
-		zap.L().Error("postRepo.GetPostByID failed",
+		logger.WithContext(ctx).Error("postRepo.GetPostByID failed",
 			zap.Int64("post_id", postID),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
@@ -271,38 +344,38 @@ func (s *postServiceStruct) DeletePost(ctx context.Context, postID int64, userID
 		return err
 	}
 
-	// 1. 删除该帖子的所有评论
-	if err := s.remarkRepo.DeleteRemarksByPostID(ctx, postID); err != nil {
-		zap.L().Error("remarkRepo.DeleteRemarksByPostID failed",
-			zap.Int64("post_id", postID),
-			zap.Error(err))
-		return entity.Wrap(entity.ErrServerBusy, err)
-	}
-
-	// 2. 软删除帖子 (status = 0)
-	err = s.postRepo.DeletePostByAuthor(ctx, postID, userID)
-	if err != nil {
-		zap.L().Error("postRepo.DeletePostByAuthor failed",
-			zap.Int64("post_id", postID),
-			zap.Int64("user_id", userID),
-			zap.Error(err))
-		return entity.Wrap(entity.ErrServerBusy, err)
-	}
+	// 1~2 在同一个事务中完成：删除评论、软删除帖子、发布 PostDeleted 事件
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// 1. 删除该帖子的所有评论
+		if err := s.remarkRepo.DeleteRemarksByPostID(ctx, postID); err != nil {
+			logger.WithContext(ctx).Error("remarkRepo.DeleteRemarksByPostID failed",
+				zap.Int64("post_id", postID),
+				zap.Error(err))
+			return err
+		}
 
-	// 3. 删除 ES 中的帖子文档
-	if s.esClient != nil {
-		postIDStr := strconv.FormatInt(postID, 10)
-		if err := s.esClient.DeleteDocument(ctx, es.IndexPost, postIDStr); err != nil {
-			zap.L().Error("esClient.DeleteDocument failed",
+		// 2. 软删除帖子 (status = 0)
+		if err := s.postRepo.DeletePostByAuthor(ctx, postID, userID); err != nil {
+			logger.WithContext(ctx).Error("postRepo.DeletePostByAuthor failed",
 				zap.Int64("post_id", postID),
+				zap.Int64("user_id", userID),
 				zap.Error(err))
-			// ES 删除失败不影响主流程，仅记录日志
+			return err
 		}
+
+		return s.events.Publish(ctx, event.PostDeleted{
+			PostID:      postID,
+			AuthorID:    userID,
+			CommunityID: post.CommunityID,
+		})
+	})
+	if err != nil {
+		return entity.Wrap(entity.ErrServerBusy, err)
 	}
 
 	// 清理 Redis 缓存
 	if err := s.postCache.DeletePost(ctx, postID, post.CommunityID); err != nil {
-		zap.L().Error("postCache.DeletePost failed",
+		logger.WithContext(ctx).Error("postCache.DeletePost failed",
 			zap.Int64("post_id", postID),
 			zap.Error(err))
 		// 缓存清理失败不影响主流程，仅记录日志
@@ -313,8 +386,8 @@ func (s *postServiceStruct) DeletePost(ctx context.Context, postID int64, userID
 
 // VoteForPost 投票业务逻辑 (Architecture D: Redis Lua + MQ 持久化)
 //
-//	请求 → Redis Lua 原子更新(ZSet+Hash+Gravity score) → 发 MQ → 返回
-//	                                                    → Consumer → MySQL UPSERT(持久化兜底)
+//	请求 → Redis Lua 原子更新(ZSet+Hash+Gravity score) → VoteCast 事件写 Outbox → 返回
+//	                                                    → OutboxRelay → MQ → Consumer → MySQL UPSERT(持久化兜底)
 func (s *postServiceStruct) VoteForPost(ctx context.Context, userID int64, p *postreq.VoteRequest) error {
 	// 领域校验
 	vote := &entity.Vote{
@@ -332,7 +405,10 @@ func (s *postServiceStruct) VoteForPost(ctx context.Context, userID int64, p *po
 	// 1. 获取 community_id (优先 Redis → 回退 MySQL)
 	communityID, err := s.postCache.GetPostCommunityID(ctx, p.PostID)
 	if err != nil {
-		// Redis 缓存缺失，回退到 MySQL 查找帖子
+		// Redis 缓存缺失，回退到 MySQL 查找帖子；不存在的帖子 ID 在查库前被拦截
+		if !s.postMayExist(ctx, p.PostID) {
+			return entity.ErrNotFound
+		}
 		post, err := s.postRepo.GetPostByID(ctx, p.PostID)
 		if err != nil {
 			return entity.Wrap(entity.ErrServerBusy, err)
@@ -343,49 +419,81 @@ func (s *postServiceStruct) VoteForPost(ctx context.Context, userID int64, p *po
 		communityID = post.CommunityID
 		// 引导 Redis 缓存，让后续投票走快路径
 		if err := s.postCache.CreatePost(ctx, p.PostID, communityID); err != nil {
-			zap.L().Error("postCache.CreatePost bootstrap failed", zap.Error(err))
+			logger.WithContext(ctx).Error("postCache.CreatePost bootstrap failed", zap.Error(err))
 		}
 	}
 	communityIDStr := strconv.FormatInt(communityID, 10)
 
 	// 2. Redis Lua 原子更新 (ZSet + Hash + Gravity score)
-	err = s.postCache.VoteForPost(ctx, userIDStr, postIDStr, communityIDStr, float64(p.Direction))
-	if err != nil {
-		if errors.Is(err, entity.ErrVoteTimeExpire) {
-			return err
+	// 先记下原投票值，事件写入失败时据此回滚，避免 Redis 与 MySQL 永久不一致
+	prevValue, prevErr := s.postCache.GetUserVote(ctx, userIDStr, postIDStr)
+	voteErr := s.postCache.VoteForPost(ctx, userIDStr, postIDStr, communityIDStr, float64(p.Direction))
+	if voteErr != nil {
+		if errors.Is(voteErr, entity.ErrVoteTimeExpire) {
+			return voteErr
 		}
-		if errors.Is(err, entity.ErrVoteRepeated) {
+		if errors.Is(voteErr, entity.ErrVoteRepeated) {
 			// 重复投票是幂等操作，不报错
 			return nil
 		}
 		// Lua 执行失败（如 Redis 宕机），记录日志但继续发 MQ 让消费者兜底
-		zap.L().Error("postCache.VoteForPost failed, fallback to MQ persistence",
+		logger.WithContext(ctx).Error("postCache.VoteForPost failed, fallback to MQ persistence",
 			zap.String("post_id", postIDStr),
 			zap.String("user_id", userIDStr),
-			zap.Error(err))
+			zap.Error(voteErr))
 	}
 
-	// 3. 异步入队 (MQ) — MySQL 异步持久化兜底
-	if s.publisher != nil {
-		msg := &mq.VoteMessage{
-			MsgID:  strconv.FormatInt(snowflake.GenID(), 10),
-			PostID: postIDStr,
-			UserID: userIDStr,
-			Action: int(p.Direction),
+	// 3. 读取投票后的赞成票数，随事件同步到搜索索引；Redis 不可用时留空由下次投票或重建索引补齐
+	voteCount := int64(-1)
+	if voteErr == nil {
+		if counts, cErr := s.postCache.GetPostsVoteData(ctx, []string{postIDStr}); cErr == nil && len(counts) == 1 {
+			voteCount = counts[0]
 		}
-		if err := s.publisher.PublishVote(ctx, msg); err != nil {
-			zap.L().Error("publish vote message failed", zap.Error(err))
+	}
+
+	// 4. 发布 VoteCast 事件 — 桥接处理器写入发件箱，由 OutboxRelay 投递到 MQ，消费者异步持久化到 MySQL
+	if err := s.events.Publish(ctx, event.VoteCast{
+		PostID:      p.PostID,
+		UserID:      userID,
+		CommunityID: communityID,
+		Direction:   p.Direction,
+		VoteCount:   voteCount,
+	}); err != nil {
+		logger.WithContext(ctx).Error("publish VoteCast event failed",
+			zap.String("post_id", postIDStr),
+			zap.String("user_id", userIDStr),
+			zap.Error(err))
+		// 发件箱写入失败意味着这次投票不会被持久化，回滚 Redis 中的投票并让用户重试
+		if voteErr == nil {
+			s.revertVote(ctx, userIDStr, postIDStr, communityIDStr, prevValue, prevErr)
 		}
+		return entity.Wrap(entity.ErrServerBusy, err)
 	}
 
 	return nil
 }
 
+// revertVote 把 Redis 中的投票恢复为原值；原值读取失败时无法回滚，只记录日志
+func (s *postServiceStruct) revertVote(ctx context.Context, userID, postID, communityID string, prevValue float64, prevErr error) {
+	log := logger.WithContext(ctx).With(zap.String("post_id", postID), zap.String("user_id", userID))
+	if prevErr != nil {
+		log.Error("cannot revert vote: previous value unknown", zap.Error(prevErr))
+		return
+	}
+	err := s.postCache.VoteForPost(context.WithoutCancel(ctx), userID, postID, communityID, prevValue)
+	if err != nil && !errors.Is(err, entity.ErrVoteRepeated) {
+		log.Error("revert vote failed", zap.Float64("prev_value", prevValue), zap.Error(err))
+	}
+}
+
 func (s *postServiceStruct) RemarkPost(ctx context.Context, req *postreq.RemarkRequest, userID int64) (remarkID uint, err error) {
 	// 1. 校验帖子是否存在
+	if !s.postMayExist(ctx, req.PostID) {
+		return 0, entity.ErrNotFound
+	}
 	post, err := s.postRepo.GetPostByID(ctx, req.PostID)
 	if err != nil {
-		zap.L().Error("remarkPost: postRepo.GetPostByID failed",
+		logger.WithContext(ctx).Error("remarkPost: postRepo.GetPostByID failed",
 			zap.Int64("post_id", req.PostID),
 			zap.Error(err))
 		return 0, entity.Wrap(entity.ErrServerBusy, err)
@@ -404,9 +512,22 @@ func (s *postServiceStruct) RemarkPost(ctx context.Context, req *postreq.RemarkR
 		return 0, err
 	}
 
-	// 3. 保存到数据库
-	if err := s.remarkRepo.CreateRemark(ctx, remark); err != nil {
-		zap.L().Error("remarkPost: remarkRepo.CreateRemark failed",
+	// 3. 保存到数据库，并在同一事务中发布 RemarkAdded 事件
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.remarkRepo.CreateRemark(ctx, remark); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, event.RemarkAdded{RemarkSnapshot: event.RemarkSnapshot{
+			RemarkID:    remark.ID,
+			PostID:      remark.PostID,
+			CommunityID: post.CommunityID,
+			AuthorID:    remark.AuthorID,
+			Content:     remark.Content,
+			CreatedAt:   remark.CreatedAt,
+		}})
+	})
+	if err != nil {
+		logger.WithContext(ctx).Error("remarkPost: remarkRepo.CreateRemark failed",
 			zap.Int64("post_id", req.PostID),
 			zap.Int64("author_id", userID),
 			zap.Error(err))
@@ -416,12 +537,116 @@ func (s *postServiceStruct) RemarkPost(ctx context.Context, req *postreq.RemarkR
 	return remark.ID, nil
 }
 
+// relatedPostsTTL 相关帖子缓存时间，内容变化不频繁，短暂缓存即可挡住详情页的大部分流量
+const relatedPostsTTL = 5 * time.Minute
+
+// GetRelatedPosts 获取与帖子内容相近的帖子
+// 优先读缓存，未命中时走 ES more_like_this；ES 不可用时回退到所在社区的热门帖子（回退结果不缓存）
+func (s *postServiceStruct) GetRelatedPosts(ctx context.Context, postID int64, p *postreq.RelatedPostsRequest) ([]*postResp.DetailResponse, error) {
+	if !s.postMayExist(ctx, postID) {
+		return nil, entity.ErrNotFound
+	}
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		logger.WithContext(ctx).Error("getRelatedPosts: postRepo.GetPostByID failed",
+			zap.Int64("post_id", postID),
+			zap.Error(err))
+		return nil, entity.Wrap(entity.ErrServerBusy, err)
+	}
+	if post == nil || post.PostID == "" {
+		return nil, entity.ErrNotFound
+	}
+
+	scope := "all"
+	var communityID int64
+	if p.SameCommunity {
+		communityID = post.CommunityID
+		scope = "c" + strconv.FormatInt(communityID, 10)
+	}
+	scope += ":" + strconv.Itoa(p.Size)
+
+	ids, ok, err := s.postCache.GetRelatedPostIDs(ctx, postID, scope)
+	if err != nil {
+		logger.WithContext(ctx).Warn("getRelatedPosts: postCache.GetRelatedPostIDs failed", zap.Error(err))
+	}
+	if !ok {
+		ids, err = s.searchRelatedPostIDs(ctx, post, communityID, p.Size)
+		if err == nil {
+			if err := s.postCache.SetRelatedPostIDs(ctx, postID, scope, ids, relatedPostsTTL); err != nil {
+				logger.WithContext(ctx).Warn("getRelatedPosts: postCache.SetRelatedPostIDs failed", zap.Error(err))
+			}
+		} else {
+			logger.WithContext(ctx).Warn("getRelatedPosts: fallback to community hot posts",
+				zap.Int64("post_id", postID),
+				zap.Error(err))
+			ids, err = s.postCache.GetCommunityPostIDsInOrder(ctx, post.CommunityID, postreq.OrderScore, 1, int64(p.Size)+1)
+			if err != nil {
+				return nil, entity.Wrap(entity.ErrServerBusy, err)
+			}
+		}
+	}
+
+	// 去掉帖子本身（回退的热门列表中可能包含）
+	related := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != post.PostID && len(related) < p.Size {
+			related = append(related, id)
+		}
+	}
+	if len(related) == 0 {
+		return []*postResp.DetailResponse{}, nil
+	}
+
+	posts, err := s.postRepo.GetPostListByIDsWithPreload(ctx, related)
+	if err != nil {
+		return nil, entity.Wrap(entity.ErrServerBusy, err)
+	}
+	// 以实际查到的帖子读取票数，避免已删除帖子造成下标错位
+	postIDs := make([]string, 0, len(posts))
+	for _, rp := range posts {
+		postIDs = append(postIDs, rp.PostID)
+	}
+	voteData, err := s.postCache.GetPostsVoteData(ctx, postIDs)
+	if err != nil {
+		logger.WithContext(ctx).Error("getRelatedPosts: postCache.GetPostsVoteData failed", zap.Error(err))
+		voteData = make([]int64, len(posts))
+	}
+
+	data := make([]*postResp.DetailResponse, 0, len(posts))
+	for idx, rp := range posts {
+		var authorName string
+		if rp.Author != nil {
+			authorName = rp.Author.UserName
+		}
+		data = append(data, &postResp.DetailResponse{
+			ID:          rp.PostID,
+			AuthorID:    strconv.FormatInt(rp.AuthorID, 10),
+			CommunityID: rp.CommunityID,
+			Status:      rp.Status,
+			Title:       rp.PostTitle,
+			Content:     rp.Content,
+			CreateTime:  rp.CreatedAt,
+			AuthorName:  authorName,
+			VoteNum:     voteData[idx],
+		})
+	}
+	return data, nil
+}
+
+// searchRelatedPostIDs 通过 ES 查找相关帖子
+func (s *postServiceStruct) searchRelatedPostIDs(ctx context.Context, post *entity.Post, communityID int64, size int) ([]string, error) {
+	if s.search == nil {
+		return nil, errors.New("search backend is not initialized")
+	}
+	return s.search.RelatedPostIDs(ctx, post.PostID, communityID, size)
+}
+
 // GetPostRemarks 获取帖子评论列表
 func (s *postServiceStruct) GetPostRemarks(ctx context.Context, postID int64) ([]*postResp.RemarkDetail, error) {
 	// 1. 获取原始评论列表
 	remarks, err := s.remarkRepo.GetRemarksByPostID(ctx, postID)
 	if err != nil {
-		zap.L().Error("getPostRemarks: remarkRepo.GetRemarksByPostID failed",
+		logger.WithContext(ctx).Error("getPostRemarks: remarkRepo.GetRemarksByPostID failed",
 			zap.Int64("post_id", postID),
 			zap.Error(err))
 		return nil, entity.Wrap(entity.ErrServerBusy, err)
@@ -446,17 +671,226 @@ func (s *postServiceStruct) GetPostRemarks(ctx context.Context, postID int64) ([
 }
 
 // SearchPosts 全文搜索帖子
-func (s *postServiceStruct) SearchPosts(ctx context.Context, keyword string, page, pageSize int) (*es.SearchResponse, error) {
-	if s.esClient == nil {
-		zap.L().Warn("esClient is not initialized")
-		return &es.SearchResponse{Posts: []es.SearchPostDoc{}}, nil
+func (s *postServiceStruct) SearchPosts(ctx context.Context, req *searchreq.SearchRequest) (*postResp.SearchResponse, error) {
+	if s.search == nil {
+		logger.WithContext(ctx).Warn("search backend is not initialized")
+		return &postResp.SearchResponse{
+			Page:     req.Page,
+			PageSize: req.PageSize,
+			Posts:    []*postResp.SearchDetail{},
+			Facets:   postResp.SearchFacets{Communities: []*postResp.CommunityFacet{}},
+		}, nil
+	}
+
+	if !req.StartDate.IsZero() && !req.EndDate.IsZero() && req.EndDate.Before(req.StartDate) {
+		return nil, entity.ErrInvalidParam
+	}
+
+	q := &domain.SearchQuery{
+		Keyword:      req.Keyword,
+		Page:         req.Page,
+		PageSize:     req.PageSize,
+		CommunityIDs: req.CommunityIDs,
+		AuthorID:     req.AuthorID,
+		CreatedFrom:  req.StartDate,
+		Sort:         req.Sort,
+		Type:         req.Type,
+	}
+	// 结束日期包含当天，转换为次日零点的开区间上限
+	if !req.EndDate.IsZero() {
+		q.CreatedTo = req.EndDate.AddDate(0, 0, 1)
+	}
+
+	result, err := s.search.Search(ctx, q)
+	if err != nil {
+		return nil, entity.Wrap(entity.ErrServerBusy, err)
+	}
+	return s.hydrateSearchResult(ctx, result), nil
+}
+
+// hydrateSearchResult 批量补全搜索结果中的作者名、票数和社区名
+// 评论命中以所属帖子为主体返回，所属帖子已删除或隐藏的评论不再展示；
+// 补全数据读取失败只记录日志并保留原始字段，不影响搜索结果本身
+func (s *postServiceStruct) hydrateSearchResult(ctx context.Context, result *domain.SearchResult) *postResp.SearchResponse {
+	resp := &postResp.SearchResponse{
+		Total:      result.Total,
+		Page:       result.Page,
+		PageSize:   result.PageSize,
+		Posts:      make([]*postResp.SearchDetail, 0, len(result.Hits)),
+		Suggestion: result.Suggestion,
+		Facets: postResp.SearchFacets{
+			Communities: make([]*postResp.CommunityFacet, 0, len(result.Communities)),
+		},
+	}
+
+	// 1. 评论所属帖子：一次查询，只返回仍处于发布状态的帖子
+	var parentIDs []string
+	for _, doc := range result.Hits {
+		if doc.Type == domain.SearchTypeRemark {
+			parentIDs = append(parentIDs, doc.PostID)
+		}
+	}
+	parents := make(map[string]*entity.Post, len(parentIDs))
+	parentsLoaded := false // 查询失败时无法判断帖子状态，保留评论命中
+	if len(parentIDs) > 0 {
+		posts, err := s.postRepo.GetPostListByIDsWithPreload(ctx, parentIDs)
+		if err != nil {
+			logger.WithContext(ctx).Error("searchPosts: postRepo.GetPostListByIDsWithPreload failed", zap.Error(err))
+		} else {
+			parentsLoaded = true
+		}
+		for _, p := range posts {
+			parents[p.PostID] = p
+		}
+	}
+	hits := make([]domain.SearchHit, 0, len(result.Hits))
+	for _, doc := range result.Hits {
+		if _, ok := parents[doc.PostID]; doc.Type == domain.SearchTypeRemark && parentsLoaded && !ok {
+			continue
+		}
+		hits = append(hits, doc)
+	}
+
+	// 2. 作者：一次查询所有帖子作者和评论作者
+	postIDs := make([]string, 0, len(hits))
+	authorIDs := make([]int64, 0, len(hits))
+	seen := make(map[int64]struct{}, len(hits))
+	addAuthor := func(id int64) {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			authorIDs = append(authorIDs, id)
+		}
+	}
+	for _, doc := range hits {
+		postIDs = append(postIDs, doc.PostID)
+		addAuthor(doc.AuthorID)
+		if p, ok := parents[doc.PostID]; ok {
+			addAuthor(p.AuthorID)
+		}
+	}
+	authorNames := make(map[int64]string, len(authorIDs))
+	if len(authorIDs) > 0 {
+		users, err := s.userRepo.GetUsersByIDs(ctx, authorIDs)
+		if err != nil {
+			logger.WithContext(ctx).Error("searchPosts: userRepo.GetUsersByIDs failed", zap.Error(err))
+		}
+		for _, u := range users {
+			authorNames[u.UserID] = u.UserName
+		}
+	}
+
+	// 3. 票数：以 Redis 为准，索引中的票数只用于排序
+	var voteData []int64
+	if len(postIDs) > 0 {
+		var err error
+		voteData, err = s.postCache.GetPostsVoteData(ctx, postIDs)
+		if err != nil {
+			logger.WithContext(ctx).Error("searchPosts: postCache.GetPostsVoteData failed", zap.Error(err))
+			voteData = nil
+		}
+	}
+
+	// 4. 社区：社区数量有限，直接取全量列表
+	communityNames := make(map[int64]string)
+	communities, err := s.commRepo.GetCommunityList(ctx)
+	if err != nil {
+		logger.WithContext(ctx).Error("searchPosts: commRepo.GetCommunityList failed", zap.Error(err))
+	}
+	for _, c := range communities {
+		communityNames[c.ID] = c.CommunityName
 	}
 
-	esReq := &es.SearchRequest{
-		Keyword:  keyword,
-		Page:     page,
-		PageSize: pageSize,
+	for idx, doc := range hits {
+		voteNum := doc.VoteCount
+		if idx < len(voteData) {
+			voteNum = voteData[idx]
+		}
+		detail := &postResp.SearchDetail{
+			Type: doc.Type,
+			DetailResponse: postResp.DetailResponse{
+				ID:          doc.PostID,
+				AuthorID:    strconv.FormatInt(doc.AuthorID, 10),
+				CommunityID: doc.CommunityID,
+				Status:      doc.Status,
+				Title:       doc.Title,
+				Content:     doc.Content,
+				CreateTime:  doc.CreatedAt,
+				AuthorName:  authorNames[doc.AuthorID],
+				VoteNum:     voteNum,
+			},
+			CommunityName:    communityNames[doc.CommunityID],
+			HighlightTitle:   doc.HighlightTitle,
+			HighlightContent: doc.HighlightContent,
+		}
+
+		if doc.Type == domain.SearchTypeRemark {
+			// 评论命中：主体换成所属帖子，评论本身和高亮片段放在 Remark 中
+			detail.Remark = &postResp.SearchRemark{
+				RemarkID:   doc.RemarkID,
+				Content:    doc.Content,
+				AuthorID:   strconv.FormatInt(doc.AuthorID, 10),
+				AuthorName: authorNames[doc.AuthorID],
+				CreateTime: doc.CreatedAt,
+				Highlight:  doc.HighlightContent,
+			}
+			detail.HighlightContent = nil
+			detail.DetailResponse = postResp.DetailResponse{
+				ID:          doc.PostID,
+				CommunityID: doc.CommunityID,
+				VoteNum:     voteNum,
+			}
+			if p, ok := parents[doc.PostID]; ok {
+				detail.AuthorID = strconv.FormatInt(p.AuthorID, 10)
+				detail.Status = p.Status
+				detail.Title = p.PostTitle
+				detail.Content = p.Content
+				detail.CreateTime = p.CreatedAt
+				detail.AuthorName = authorNames[p.AuthorID]
+			}
+		}
+		resp.Posts = append(resp.Posts, detail)
+	}
+	for _, f := range result.Communities {
+		resp.Facets.Communities = append(resp.Facets.Communities, &postResp.CommunityFacet{
+			CommunityID:   f.CommunityID,
+			CommunityName: communityNames[f.CommunityID],
+			Count:         f.Count,
+		})
+	}
+	return resp
+}
+
+// SuggestPosts 搜索框输入联想
+// 标题来自搜索后端的前缀联想；社区数量有限，直接对社区列表做前缀匹配
+func (s *postServiceStruct) SuggestPosts(ctx context.Context, req *searchreq.SuggestRequest) (*postResp.SuggestResponse, error) {
+	resp := &postResp.SuggestResponse{
+		Titles:      []string{},
+		Communities: []*postResp.SuggestedCommunity{},
 	}
 
-	return s.esClient.Search(ctx, esReq)
+	communities, err := s.commRepo.GetCommunityList(ctx)
+	if err != nil {
+		return nil, entity.Wrap(entity.ErrServerBusy, err)
+	}
+	prefix := strings.ToLower(req.Prefix)
+	for _, c := range communities {
+		if strings.HasPrefix(strings.ToLower(c.CommunityName), prefix) {
+			resp.Communities = append(resp.Communities, &postResp.SuggestedCommunity{
+				CommunityID:   c.ID,
+				CommunityName: c.CommunityName,
+			})
+		}
+	}
+
+	if s.search == nil {
+		return resp, nil
+	}
+	titles, err := s.search.Suggest(ctx, req.Prefix, req.Size)
+	if err != nil {
+		// 联想失败不影响用户继续输入，只返回社区结果
+		logger.WithContext(ctx).Warn("searchSuggest: search.Suggest failed", zap.String("prefix", req.Prefix), zap.Error(err))
+		return resp, nil
+	}
+	resp.Titles = titles
+	return resp, nil
 }
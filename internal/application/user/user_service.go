@@ -20,11 +20,17 @@ import (
 	// 错误处理
 	"bluebell/internal/domain/entity"
 
+	// 日志（携带请求 ID、用户 ID 等上下文字段）
+	"bluebell/internal/infrastructure/logger"
+
+	// 领域事件
+	"bluebell/internal/domain/event"
+
 	"context"
 	"errors"
 	"fmt"
 	"strings"
-	"time"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
@@ -33,16 +39,22 @@ import (
 type userServiceStruct struct {
 	userRepo   domain.UserRepository
 	tokenCache domain.UserTokenCacheRepository
-	jwtCfg     *config.Config
+	events     domain.EventPublisher
+	jwtCfg     atomic.Pointer[config.Config] // 配置热更新后替换，新签发的 Token 使用新的有效期
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(userRepo domain.UserRepository, tokenCache domain.UserTokenCacheRepository, jwtCfg *config.Config) application.UserService {
-	return &userServiceStruct{
+func NewUserService(userRepo domain.UserRepository, tokenCache domain.UserTokenCacheRepository, events domain.EventPublisher, jwtCfg *config.Config) application.UserService {
+	s := &userServiceStruct{
 		userRepo:   userRepo,
 		tokenCache: tokenCache,
-		jwtCfg:     jwtCfg,
+		events:     events,
 	}
+	s.jwtCfg.Store(jwtCfg)
+	config.Subscribe(func(_, new *config.Config) {
+		s.jwtCfg.Store(new)
+	})
+	return s
 }
 
 // SignUp 处理用户注册业务逻辑
@@ -51,7 +63,7 @@ func (s *userServiceStruct) SignUp(ctx context.Context, p *userreq.SignUpRequest
 		if errors.Is(err, entity.ErrUserExist) {
 			return err
 		}
-		zap.L().Error("userRepo.CheckUserExist failed",
+		logger.WithContext(ctx).Error("userRepo.CheckUserExist failed",
 			zap.String("username", p.Username),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
@@ -63,7 +75,7 @@ func (s *userServiceStruct) SignUp(ctx context.Context, p *userreq.SignUpRequest
 	// 2. 密码加密 (下沉到领域层)
 	hashedPassword, err := entity.HashPassword(p.Password)
 	if err != nil {
-		zap.L().Error("entity.HashPassword failed", zap.Error(err))
+		logger.WithContext(ctx).Error("entity.HashPassword failed", zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
 	}
 
@@ -77,18 +89,26 @@ func (s *userServiceStruct) SignUp(ctx context.Context, p *userreq.SignUpRequest
 
 	err = s.userRepo.InsertUser(ctx, u)
 	if err != nil {
-		zap.L().Error("userRepo.InsertUser failed",
+		logger.WithContext(ctx).Error("userRepo.InsertUser failed",
 			zap.Int64("user_id", u.UserID),
 			zap.String("username", p.Username),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
 	}
 
+	// 4. 发布 UserRegistered 事件，处理器失败不影响注册结果
+	if err := s.events.Publish(ctx, event.UserRegistered{UserID: u.UserID, UserName: u.UserName}); err != nil {
+		logger.WithContext(ctx).Error("publish UserRegistered event failed",
+			zap.Int64("user_id", u.UserID),
+			zap.Error(err))
+	}
+
 	return nil
 }
 
 // Login 处理用户登录业务逻辑
 func (s *userServiceStruct) Login(ctx context.Context, p *userreq.LoginRequest) (string, string, error) {
+	cfg := s.jwtCfg.Load()
 	user := &entity.User{
 		UserName: p.Username,
 		Password: p.Password,
@@ -99,34 +119,23 @@ func (s *userServiceStruct) Login(ctx context.Context, p *userreq.LoginRequest)
 		if errors.Is(err, entity.ErrUserNotExist) || errors.Is(err, entity.ErrInvalidPassword) {
 			return "", "", err
 		}
-		zap.L().Error("userRepo.CheckLogin failed",
+		logger.WithContext(ctx).Error("userRepo.CheckLogin failed",
 			zap.String("username", p.Username),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
 	}
 
-	aToken, rToken, err := jwt.GenToken(s.jwtCfg, user.UserID)
+	aToken, rToken, err := jwt.GenToken(cfg, user.UserID)
 	if err != nil {
-		zap.L().Error("jwt.GenToken failed",
+		logger.WithContext(ctx).Error("jwt.GenToken failed",
 			zap.Int64("user_id", user.UserID),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
 	}
 
-	accessTokenExp, err := time.ParseDuration(s.jwtCfg.JWT.AccessExpiry)
-	if err != nil {
-		zap.L().Error("parse access token expiry failed", zap.Error(err))
-		accessTokenExp = 2 * time.Hour // 默认 2 小时
-	}
-	refreshTokenExp, err := time.ParseDuration(s.jwtCfg.JWT.RefreshExpiry)
-	if err != nil {
-		zap.L().Error("parse refresh token expiry failed", zap.Error(err))
-		refreshTokenExp = 7 * 24 * time.Hour // 默认 7 天
-	}
-
-	err = s.tokenCache.SetUserToken(ctx, user.UserID, aToken, rToken, accessTokenExp, refreshTokenExp)
+	err = s.tokenCache.SetUserToken(ctx, user.UserID, aToken, rToken, cfg.JWT.AccessExpiry, cfg.JWT.RefreshExpiry)
 	if err != nil {
-		zap.L().Error("tokenCache.SetUserToken failed",
+		logger.WithContext(ctx).Error("tokenCache.SetUserToken failed",
 			zap.Int64("user_id", user.UserID),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
@@ -137,6 +146,7 @@ func (s *userServiceStruct) Login(ctx context.Context, p *userreq.LoginRequest)
 
 // RefreshToken 刷新 Token
 func (s *userServiceStruct) RefreshToken(ctx context.Context, p *userreq.RefreshTokenRequest) (newAToken, newRToken string, err error) {
+	cfg := s.jwtCfg.Load()
 	// 1. 解析 Authorization Header 获取 Access Token
 	parts := strings.SplitN(p.Authorization, " ", 2)
 	if !(len(parts) == 2 && parts[0] == "Bearer") {
@@ -145,7 +155,7 @@ func (s *userServiceStruct) RefreshToken(ctx context.Context, p *userreq.Refresh
 	// aToken := parts[1] // aToken 暂时没有使用，但可以在此验证或者做其他逻辑
 
 	// 2. 解析 Refresh Token 获取 UserID
-	userID, err := jwt.ParseToken(s.jwtCfg, p.RefreshToken, jwt.RefreshTokenType)
+	userID, err := jwt.ParseToken(cfg, p.RefreshToken, jwt.RefreshTokenType)
 	if err != nil {
 		return "", "", entity.ErrInvalidToken
 	}
@@ -153,34 +163,23 @@ func (s *userServiceStruct) RefreshToken(ctx context.Context, p *userreq.Refresh
 	// 3. 检查用户是否存在
 	user, err := s.userRepo.CheckUserExistsByID(ctx, userID)
 	if err != nil || user == nil {
-		zap.L().Error("userRepo.CheckUserExistsByID failed",
+		logger.WithContext(ctx).Error("userRepo.CheckUserExistsByID failed",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
 	}
 
-	newAToken, newRToken, err = jwt.GenToken(s.jwtCfg, user.UserID)
+	newAToken, newRToken, err = jwt.GenToken(cfg, user.UserID)
 	if err != nil {
-		zap.L().Error("jwt.GenToken failed in refresh",
+		logger.WithContext(ctx).Error("jwt.GenToken failed in refresh",
 			zap.Int64("user_id", user.UserID),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
 	}
 
-	accessTokenExp, err := time.ParseDuration(s.jwtCfg.JWT.AccessExpiry)
-	if err != nil {
-		zap.L().Error("parse access token expiry failed in refresh", zap.Error(err))
-		accessTokenExp = 2 * time.Hour // 默认 2 小时
-	}
-	refreshTokenExp, err := time.ParseDuration(s.jwtCfg.JWT.RefreshExpiry)
-	if err != nil {
-		zap.L().Error("parse refresh token expiry failed in refresh", zap.Error(err))
-		refreshTokenExp = 7 * 24 * time.Hour // 默认 7 天
-	}
-
-	err = s.tokenCache.SetUserToken(ctx, user.UserID, newAToken, newRToken, accessTokenExp, refreshTokenExp)
+	err = s.tokenCache.SetUserToken(ctx, user.UserID, newAToken, newRToken, cfg.JWT.AccessExpiry, cfg.JWT.RefreshExpiry)
 	if err != nil {
-		zap.L().Error("tokenCache.SetUserToken failed in refresh",
+		logger.WithContext(ctx).Error("tokenCache.SetUserToken failed in refresh",
 			zap.Int64("user_id", user.UserID),
 			zap.Error(err))
 		return "", "", entity.Wrap(entity.ErrServerBusy, err)
@@ -192,7 +191,7 @@ func (s *userServiceStruct) RefreshToken(ctx context.Context, p *userreq.Refresh
 // Logout 用户登出，清除 Redis 中的 Token
 func (s *userServiceStruct) Logout(ctx context.Context, userID int64) error {
 	if err := s.tokenCache.DeleteUserToken(ctx, userID); err != nil {
-		zap.L().Error("tokenCache.DeleteUserToken failed",
+		logger.WithContext(ctx).Error("tokenCache.DeleteUserToken failed",
 			zap.Int64("user_id", userID),
 			zap.Error(err))
 		return entity.Wrap(entity.ErrServerBusy, err)
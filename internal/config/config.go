@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -32,6 +35,9 @@ type mysqlConfig struct {
 	DbName       string `mapstructure:"db_name"`
 	MaxOpenConns int    `mapstructure:"max_open_conns"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+
+	MigrateOnStart     bool          `mapstructure:"migrate_on_start"`     // 服务启动时执行未执行的迁移，多副本同时启动时由迁移锁保证只执行一次
+	MigrateLockTimeout time.Duration `mapstructure:"migrate_lock_timeout"` // 等待其他进程释放迁移锁的最长时间
 }
 
 type redisConfig struct {
@@ -43,16 +49,39 @@ type redisConfig struct {
 }
 
 type rateLimitConfig struct {
-	FillInterval string `mapstructure:"fill_interval"`
-	Capacity     int64  `mapstructure:"capacity"`
+	FillInterval time.Duration `mapstructure:"fill_interval"`
+	Capacity     int64         `mapstructure:"capacity"`
+
+	// 搜索联想按客户端限流：每个客户端的令牌填充间隔和桶容量
+	SuggestFillInterval time.Duration `mapstructure:"suggest_fill_interval"`
+	SuggestCapacity     int64         `mapstructure:"suggest_capacity"`
 }
 
 type timeoutConfig struct {
-	Timeout string `mapstructure:"timeout"`
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 type rabbitmqConfig struct {
-	URL string `mapstructure:"url"`
+	Driver         string        `mapstructure:"driver"` // 消息队列实现：rabbitmq（默认）或 memory（进程内，单进程开发模式）
+	URL            string        `mapstructure:"url"`
+	MaxAttempts    int           `mapstructure:"max_attempts"`     // 消费失败的最大尝试次数，超过后进入死信队列
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"` // 首次重试的退避时长，之后每次翻倍
+	RetryMaxDelay  time.Duration `mapstructure:"retry_max_delay"`  // 退避时长上限
+
+	ReconnectMinDelay time.Duration `mapstructure:"reconnect_min_delay"` // 断线后首次重连的等待时长，之后每次翻倍
+	ReconnectMaxDelay time.Duration `mapstructure:"reconnect_max_delay"` // 重连等待时长上限
+
+	VoteBatchSize     int           `mapstructure:"vote_batch_size"`     // 投票消费者单批最多合并的消息数，同时作为 prefetch
+	VoteFlushInterval time.Duration `mapstructure:"vote_flush_interval"` // 未攒满一批时的最长等待时间
+}
+
+type outboxConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 中继轮询间隔
+	BatchSize    int           `mapstructure:"batch_size"`    // 每批投递的消息数
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // 单条消息最大投递次数，超过后标记为失败
+
+	RetentionDays   int           `mapstructure:"retention_days"`   // 已投递消息的保留天数，过期后定期删除
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // 清理任务的执行间隔
 }
 
 type SnowflakeConfig struct {
@@ -61,9 +90,15 @@ type SnowflakeConfig struct {
 }
 
 type jwtConfig struct {
-	Secret        string `mapstructure:"secret"`
-	AccessExpiry  string `mapstructure:"access_expiry"`
-	RefreshExpiry string `mapstructure:"refresh_expiry"`
+	Secret        string        `mapstructure:"secret"`
+	AccessExpiry  time.Duration `mapstructure:"access_expiry"`
+	RefreshExpiry time.Duration `mapstructure:"refresh_expiry"`
+}
+
+type idempotencyConfig struct {
+	TTL         time.Duration `mapstructure:"ttl"`          // 首次响应的保存时长
+	LockTTL     time.Duration `mapstructure:"lock_ttl"`     // 首个请求处理中占位的最长时长
+	WaitTimeout time.Duration `mapstructure:"wait_timeout"` // 并发重复请求等待首个请求完成的最长时长
 }
 
 type esConfig struct {
@@ -72,8 +107,56 @@ type esConfig struct {
 	Password  string   `mapstructure:"password"`
 }
 
+type searchConfig struct {
+	Driver       string `mapstructure:"driver"`        // elasticsearch（默认）| embedded
+	EmbeddedPath string `mapstructure:"embedded_path"` // embedded 驱动的索引快照文件路径
+}
+
+type tracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`     // otlp（默认，gRPC）| stdout | file
+	Endpoint    string  `mapstructure:"endpoint"`     // otlp 导出地址，如 localhost:4317
+	FilePath    string  `mapstructure:"file_path"`    // file 导出的文件路径
+	ServiceName string  `mapstructure:"service_name"` // 上报的服务名
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 根 Span 采样比例，0~1，未配置时全部采样
+}
+
+type healthConfig struct {
+	CheckTimeout     time.Duration `mapstructure:"check_timeout"`      // 就绪检查中单个依赖的超时时间
+	VoteConsumerPort int           `mapstructure:"vote_consumer_port"` // 投票消费者的健康检查端口
+	SyncConsumerPort int           `mapstructure:"sync_consumer_port"` // 搜索同步消费者的健康检查端口
+}
+
+type cacheConfig struct {
+	PostTTL         time.Duration `mapstructure:"post_ttl"`          // 帖子详情缓存的过期时间
+	PostTTLJitter   time.Duration `mapstructure:"post_ttl_jitter"`   // 过期时间上叠加的随机时长上限，0 表示不加
+	PostNegativeTTL time.Duration `mapstructure:"post_negative_ttl"` // 不存在的帖子占位值的过期时间
+}
+
+type seedConfig struct {
+	DefaultCommunities bool   `mapstructure:"default_communities"` // 社区表为空时写入默认社区
+	AdminUsername      string `mapstructure:"admin_username"`
+	AdminPassword      string `mapstructure:"admin_password"` // 为空时不创建管理员，应通过 SEED_ADMIN_PASSWORD 或 SEED_ADMIN_PASSWORD_FILE 提供
+}
+
+type bloomConfig struct {
+	ExpectedPosts     int64   `mapstructure:"expected_posts"`      // 预计帖子总数，超出后误判率上升，应留有余量
+	FalsePositiveRate float64 `mapstructure:"false_positive_rate"` // 目标误判率，0~1
+	RebuildBatchSize  int     `mapstructure:"rebuild_batch_size"`  // 从 MySQL 重建时每批读取的帖子数
+}
+
+type localCacheEntry struct {
+	Size int           `mapstructure:"size"` // 最大条目数，超出后淘汰最久未使用的项
+	TTL  time.Duration `mapstructure:"ttl"`  // 条目过期时间，也是多副本间失效消息丢失时的最长不一致时间
+}
+
+type localCacheConfig struct {
+	Channel string                     `mapstructure:"channel"` // 广播失效消息的 Redis Pub/Sub 频道
+	Caches  map[string]localCacheEntry `mapstructure:"caches"`  // 按缓存名称配置，见 localcache.Name* 常量
+}
+
 // Config 全局配置结构体
-// 使用指针类型以区分配置缺失和零值
+// 各节使用指针类型；加载时每一节都有默认值，经 Load / Init 得到的配置中各节均不为 nil
 type Config struct {
 	App       *appConfig       `mapstructure:"app"`
 	Mysql     *mysqlConfig     `mapstructure:"mysql"`
@@ -85,10 +168,27 @@ type Config struct {
 	Timeout   *timeoutConfig   `mapstructure:"timeout"`
 	RabbitMQ  *rabbitmqConfig  `mapstructure:"rabbitmq"`
 	ES        *esConfig        `mapstructure:"es"`
+	Search    *searchConfig    `mapstructure:"search"`
+	Tracing   *tracingConfig   `mapstructure:"tracing"`
+	Health    *healthConfig    `mapstructure:"health"`
+	Cache     *cacheConfig     `mapstructure:"cache"`
+	Bloom     *bloomConfig     `mapstructure:"bloom"`
+	Seed      *seedConfig      `mapstructure:"seed"`
+
+	LocalCache *localCacheConfig `mapstructure:"local_cache"`
+
+	Idempotency *idempotencyConfig `mapstructure:"idempotency"`
+	Outbox      *outboxConfig      `mapstructure:"outbox"`
 }
 
 var atva atomic.Value
 
+var (
+	subMu       sync.Mutex
+	subscribers = map[int]func(old, new *Config){}
+	nextSubID   int
+)
+
 // Get returns the current configuration
 func Get() *Config {
 	if c, ok := atva.Load().(*Config); ok {
@@ -97,35 +197,110 @@ func Get() *Config {
 	return nil
 }
 
-// Init Initialize configuration from file using Viper
-func Init(filePath string) (*Config, error) {
-	// 允许使用环境变量覆盖配置
-	viper.AutomaticEnv()
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+// Subscribe 订阅配置热更新，配置文件变更且通过校验后按订阅顺序依次回调
+// 回调在配置监听协程中执行，应尽快返回；返回的函数用于取消订阅
+func Subscribe(fn func(old, new *Config)) (unsubscribe func()) {
+	subMu.Lock()
+	defer subMu.Unlock()
+	id := nextSubID
+	nextSubID++
+	subscribers[id] = fn
+	return func() {
+		subMu.Lock()
+		defer subMu.Unlock()
+		delete(subscribers, id)
+	}
+}
 
-	viper.SetConfigFile(filePath)
-	err := viper.ReadInConfig()
-	if err != nil {
-		return nil, fmt.Errorf("Read config failed: %w", err)
+// notify 通知所有订阅者
+func notify(old, new *Config) {
+	subMu.Lock()
+	ids := make([]int, 0, len(subscribers))
+	for id := range subscribers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	fns := make([]func(old, new *Config), 0, len(ids))
+	for _, id := range ids {
+		fns = append(fns, subscribers[id])
 	}
+	subMu.Unlock()
 
-	conf := &Config{}
-	if err := viper.Unmarshal(conf); err != nil {
-		return nil, fmt.Errorf("Unmarshal config failed: %w", err)
+	for _, fn := range fns {
+		fn(old, new)
+	}
+}
+
+// Load 读取并校验配置文件，不设置全局配置也不监听变更，用于 config check 等一次性场景
+func Load(filePath string) (*Config, error) {
+	v := viper.New()
+	if err := read(v, filePath); err != nil {
+		return nil, err
+	}
+	return decode(v)
+}
+
+// Init Initialize configuration from file using Viper
+// 配置来源优先级：*_FILE 指向的密钥文件 > 环境变量 > 配置文件 > 默认值
+func Init(filePath string) (*Config, error) {
+	v := viper.GetViper()
+	if err := read(v, filePath); err != nil {
+		return nil, err
+	}
+	conf, err := decode(v)
+	if err != nil {
+		return nil, err
 	}
 	//把这个对象安全地发布给其他并发读取的 goroutine。
 	atva.Store(conf)
-	viper.WatchConfig()
-	viper.OnConfigChange(func(in fsnotify.Event) {
+	v.WatchConfig()
+	v.OnConfigChange(func(in fsnotify.Event) {
 		fmt.Printf("Config file changed: %s\n", in.Name)
-		newConf := new(Config)
 		// On reload, unmarshal to a completely new object
-		if err := viper.Unmarshal(newConf); err != nil {
-			fmt.Printf("Config hot reload failed: %v\n", err)
-		} else {
-			atva.Store(newConf)
+		// 校验不通过的配置不会替换当前配置，进程继续使用旧值
+		newConf, err := decode(v)
+		if err != nil {
+			fmt.Printf("Config hot reload rejected: %v\n", err)
+			return
 		}
+		old := Get()
+		if err := validateReload(old, newConf); err != nil {
+			fmt.Printf("Config hot reload rejected: %v\n", err)
+			return
+		}
+		atva.Store(newConf)
+		notify(old, newConf)
 	})
 
 	return conf, nil
 }
+
+// read 设置默认值、环境变量和密钥来源后读取配置文件
+func read(v *viper.Viper, filePath string) error {
+	setDefaults(v)
+
+	// 允许使用环境变量覆盖配置，如 MYSQL_PASSWD 覆盖 mysql.passwd
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	if err := loadSecrets(v); err != nil {
+		return err
+	}
+
+	v.SetConfigFile(filePath)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("Read config failed: %w", err)
+	}
+	return nil
+}
+
+// decode 反序列化为新的配置对象并校验，校验会一次性报告所有问题
+func decode(v *viper.Viper) (*Config, error) {
+	conf := &Config{}
+	if err := v.Unmarshal(conf); err != nil {
+		return nil, fmt.Errorf("Unmarshal config failed: %w", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid config:\n%w", err)
+	}
+	return conf, nil
+}
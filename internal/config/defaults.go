@@ -0,0 +1,108 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// setDefaults 设置各配置项的默认值
+// 每一节至少有一个默认值，保证反序列化后各节指针均不为 nil；
+// 密钥和与部署环境强相关的项（jwt.secret、rabbitmq.url、snowflake.start_time）没有默认值，由校验要求显式配置；
+// seed.admin_password 只在执行 migrate seed 时使用，为空时不创建管理员
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("app.name", "bluebell")
+	v.SetDefault("app.mode", "release")
+	v.SetDefault("app.port", 8080)
+
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.file_name", "bluebell.log")
+	v.SetDefault("log.max_size", 100)
+	v.SetDefault("log.max_backups", 7)
+	v.SetDefault("log.max_age", 30)
+
+	v.SetDefault("snowflake.machine_id", 1)
+
+	v.SetDefault("mysql.host", "127.0.0.1")
+	v.SetDefault("mysql.port", 3306)
+	v.SetDefault("mysql.user", "root")
+	v.SetDefault("mysql.passwd", "")
+	v.SetDefault("mysql.db_name", "bluebell")
+	v.SetDefault("mysql.max_open_conns", 50)
+	v.SetDefault("mysql.max_idle_conns", 25)
+	v.SetDefault("mysql.migrate_on_start", false)
+	v.SetDefault("mysql.migrate_lock_timeout", time.Minute)
+
+	v.SetDefault("redis.host", "127.0.0.1")
+	v.SetDefault("redis.port", 6379)
+	v.SetDefault("redis.password", "")
+	v.SetDefault("redis.db_name", 0)
+	v.SetDefault("redis.pool_size", 100)
+
+	v.SetDefault("ratelimit.fill_interval", 10*time.Millisecond)
+	v.SetDefault("ratelimit.capacity", 200)
+	v.SetDefault("ratelimit.suggest_fill_interval", 100*time.Millisecond)
+	v.SetDefault("ratelimit.suggest_capacity", 10)
+
+	v.SetDefault("timeout.timeout", 30*time.Second)
+
+	v.SetDefault("idempotency.ttl", 24*time.Hour)
+	v.SetDefault("idempotency.lock_ttl", 30*time.Second)
+	v.SetDefault("idempotency.wait_timeout", 3*time.Second)
+
+	v.SetDefault("jwt.secret", "")
+	v.SetDefault("jwt.access_expiry", 2*time.Hour)
+	v.SetDefault("jwt.refresh_expiry", 7*24*time.Hour)
+
+	v.SetDefault("rabbitmq.driver", "rabbitmq")
+	v.SetDefault("rabbitmq.url", "")
+	v.SetDefault("rabbitmq.max_attempts", 5)
+	v.SetDefault("rabbitmq.retry_base_delay", time.Second)
+	v.SetDefault("rabbitmq.retry_max_delay", 5*time.Minute)
+	v.SetDefault("rabbitmq.reconnect_min_delay", time.Second)
+	v.SetDefault("rabbitmq.reconnect_max_delay", 30*time.Second)
+	v.SetDefault("rabbitmq.vote_batch_size", 100)
+	v.SetDefault("rabbitmq.vote_flush_interval", 200*time.Millisecond)
+
+	v.SetDefault("outbox.poll_interval", time.Second)
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 10)
+	v.SetDefault("outbox.retention_days", 7)
+	v.SetDefault("outbox.cleanup_interval", time.Hour)
+
+	v.SetDefault("es.addresses", []string{})
+	v.SetDefault("es.username", "")
+	v.SetDefault("es.password", "")
+
+	v.SetDefault("search.driver", "elasticsearch")
+	v.SetDefault("search.embedded_path", "./data/search/index.json")
+
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "otlp")
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.file_path", "./logs/traces.jsonl")
+	v.SetDefault("tracing.service_name", "bluebell")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+
+	v.SetDefault("health.check_timeout", time.Second)
+	v.SetDefault("health.vote_consumer_port", 8091)
+	v.SetDefault("health.sync_consumer_port", 8092)
+
+	v.SetDefault("cache.post_ttl", 10*time.Minute)
+	v.SetDefault("cache.post_ttl_jitter", 2*time.Minute)
+	v.SetDefault("cache.post_negative_ttl", 30*time.Second)
+
+	v.SetDefault("seed.default_communities", true)
+	v.SetDefault("seed.admin_username", "admin")
+	v.SetDefault("seed.admin_password", "")
+
+	v.SetDefault("bloom.expected_posts", 10_000_000)
+	v.SetDefault("bloom.false_positive_rate", 0.01)
+	v.SetDefault("bloom.rebuild_batch_size", 1000)
+
+	v.SetDefault("local_cache.channel", "bluebell:localcache:invalidate")
+	v.SetDefault("local_cache.caches.community_list.size", 1)
+	v.SetDefault("local_cache.caches.community_list.ttl", time.Minute)
+	v.SetDefault("local_cache.caches.hot_feed.size", 64)
+	v.SetDefault("local_cache.caches.hot_feed.ttl", 5*time.Second)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretKeys 可以不写在配置文件中的密钥项
+// 每一项都可以通过环境变量（如 MYSQL_PASSWD）或 <环境变量>_FILE 指向的文件（如 Docker / Kubernetes Secret 挂载的
+// /run/secrets/mysql_passwd）提供；两者同时存在时以文件为准
+var secretKeys = []string{
+	"mysql.passwd",
+	"redis.password",
+	"jwt.secret",
+	"rabbitmq.url",
+	"es.password",
+	"seed.admin_password",
+}
+
+// secretEnv 返回配置项对应的环境变量名
+func secretEnv(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// loadSecrets 读取 *_FILE 指向的密钥文件，覆盖配置文件和环境变量中的值
+func loadSecrets(v *viper.Viper) error {
+	for _, key := range secretKeys {
+		path := os.Getenv(secretEnv(key) + "_FILE")
+		if path == "" {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read secret %s from %s_FILE failed: %w", key, secretEnv(key), err)
+		}
+		// 密钥文件通常以换行结尾
+		v.Set(key, strings.TrimRight(string(b), "\r\n"))
+	}
+	return nil
+}
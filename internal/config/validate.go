@@ -0,0 +1,232 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// validator 收集校验问题，全部检查完后一次性返回
+type validator struct {
+	errs []error
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.addf("%s: is required", field)
+	}
+}
+
+func (v *validator) positive(field string, value int64) {
+	if value <= 0 {
+		v.addf("%s: must be positive, got %d", field, value)
+	}
+}
+
+func (v *validator) positiveDuration(field string, value time.Duration) {
+	if value <= 0 {
+		v.addf("%s: must be a positive duration, got %s", field, value)
+	}
+}
+
+func (v *validator) port(field string, value int) {
+	if value < 1 || value > 65535 {
+		v.addf("%s: must be between 1 and 65535, got %d", field, value)
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.addf("%s: must be one of %v, got %q", field, allowed, value)
+	}
+}
+
+// section 配置节缺失时记录问题并返回 false，调用方跳过该节的其余检查
+func (v *validator) section(name string, present bool) bool {
+	if !present {
+		v.addf("%s: section is missing", name)
+	}
+	return present
+}
+
+// Validate 校验配置，启动和热更新时都会执行，返回的错误包含所有问题（每行一条）
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if v.section("app", c.App != nil) {
+		v.required("app.name", c.App.Name)
+		v.oneOf("app.mode", c.App.Mode, "dev", "test", "release")
+		v.port("app.port", c.App.Port)
+	}
+
+	if v.section("log", c.Log != nil) {
+		if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+			v.addf("log.level: %v", err)
+		}
+		v.required("log.file_name", c.Log.FileName)
+	}
+
+	if v.section("snowflake", c.Snowflake != nil) {
+		v.positive("snowflake.start_time", c.Snowflake.StartTime)
+		if c.Snowflake.MachineID < 0 || c.Snowflake.MachineID > 1023 {
+			v.addf("snowflake.machine_id: must be between 0 and 1023, got %d", c.Snowflake.MachineID)
+		}
+	}
+
+	if v.section("mysql", c.Mysql != nil) {
+		v.required("mysql.host", c.Mysql.Host)
+		v.port("mysql.port", c.Mysql.Port)
+		v.required("mysql.user", c.Mysql.User)
+		v.required("mysql.db_name", c.Mysql.DbName)
+		v.positive("mysql.max_open_conns", int64(c.Mysql.MaxOpenConns))
+		if c.Mysql.MaxIdleConns < 0 || c.Mysql.MaxIdleConns > c.Mysql.MaxOpenConns {
+			v.addf("mysql.max_idle_conns: must be between 0 and max_open_conns (%d), got %d", c.Mysql.MaxOpenConns, c.Mysql.MaxIdleConns)
+		}
+		v.positiveDuration("mysql.migrate_lock_timeout", c.Mysql.MigrateLockTimeout)
+	}
+
+	if v.section("redis", c.Redis != nil) {
+		v.required("redis.host", c.Redis.Host)
+		v.port("redis.port", c.Redis.Port)
+		v.positive("redis.pool_size", int64(c.Redis.PoolSize))
+	}
+
+	if v.section("ratelimit", c.RateLimit != nil) {
+		v.positiveDuration("ratelimit.fill_interval", c.RateLimit.FillInterval)
+		v.positive("ratelimit.capacity", c.RateLimit.Capacity)
+		v.positiveDuration("ratelimit.suggest_fill_interval", c.RateLimit.SuggestFillInterval)
+		v.positive("ratelimit.suggest_capacity", c.RateLimit.SuggestCapacity)
+	}
+
+	if v.section("timeout", c.Timeout != nil) {
+		v.positiveDuration("timeout.timeout", c.Timeout.Timeout)
+	}
+
+	if v.section("idempotency", c.Idempotency != nil) {
+		v.positiveDuration("idempotency.ttl", c.Idempotency.TTL)
+		v.positiveDuration("idempotency.lock_ttl", c.Idempotency.LockTTL)
+		v.positiveDuration("idempotency.wait_timeout", c.Idempotency.WaitTimeout)
+	}
+
+	if v.section("jwt", c.JWT != nil) {
+		v.required("jwt.secret", c.JWT.Secret)
+		v.positiveDuration("jwt.access_expiry", c.JWT.AccessExpiry)
+		v.positiveDuration("jwt.refresh_expiry", c.JWT.RefreshExpiry)
+		if c.JWT.RefreshExpiry < c.JWT.AccessExpiry {
+			v.addf("jwt.refresh_expiry: must not be shorter than access_expiry (%s), got %s", c.JWT.AccessExpiry, c.JWT.RefreshExpiry)
+		}
+	}
+
+	if v.section("rabbitmq", c.RabbitMQ != nil) {
+		v.oneOf("rabbitmq.driver", c.RabbitMQ.Driver, "rabbitmq", "memory")
+		if c.RabbitMQ.Driver == "rabbitmq" {
+			v.required("rabbitmq.url", c.RabbitMQ.URL)
+		}
+		v.positive("rabbitmq.max_attempts", int64(c.RabbitMQ.MaxAttempts))
+		v.positiveDuration("rabbitmq.retry_base_delay", c.RabbitMQ.RetryBaseDelay)
+		if c.RabbitMQ.RetryMaxDelay < c.RabbitMQ.RetryBaseDelay {
+			v.addf("rabbitmq.retry_max_delay: must not be shorter than retry_base_delay (%s), got %s", c.RabbitMQ.RetryBaseDelay, c.RabbitMQ.RetryMaxDelay)
+		}
+		v.positiveDuration("rabbitmq.reconnect_min_delay", c.RabbitMQ.ReconnectMinDelay)
+		if c.RabbitMQ.ReconnectMaxDelay < c.RabbitMQ.ReconnectMinDelay {
+			v.addf("rabbitmq.reconnect_max_delay: must not be shorter than reconnect_min_delay (%s), got %s", c.RabbitMQ.ReconnectMinDelay, c.RabbitMQ.ReconnectMaxDelay)
+		}
+		v.positive("rabbitmq.vote_batch_size", int64(c.RabbitMQ.VoteBatchSize))
+		v.positiveDuration("rabbitmq.vote_flush_interval", c.RabbitMQ.VoteFlushInterval)
+	}
+
+	if v.section("outbox", c.Outbox != nil) {
+		v.positiveDuration("outbox.poll_interval", c.Outbox.PollInterval)
+		v.positive("outbox.batch_size", int64(c.Outbox.BatchSize))
+		v.positive("outbox.max_attempts", int64(c.Outbox.MaxAttempts))
+		v.positive("outbox.retention_days", int64(c.Outbox.RetentionDays))
+		v.positiveDuration("outbox.cleanup_interval", c.Outbox.CleanupInterval)
+	}
+
+	// ES 是可选依赖，未配置地址时搜索不可用但不影响启动，这里不要求 es.addresses
+	if v.section("search", c.Search != nil) {
+		v.oneOf("search.driver", c.Search.Driver, "elasticsearch", "embedded")
+		if c.Search.Driver == "embedded" {
+			v.required("search.embedded_path", c.Search.EmbeddedPath)
+		}
+	}
+
+	if v.section("tracing", c.Tracing != nil) && c.Tracing.Enabled {
+		v.oneOf("tracing.exporter", c.Tracing.Exporter, "otlp", "stdout", "file")
+		switch c.Tracing.Exporter {
+		case "otlp":
+			v.required("tracing.endpoint", c.Tracing.Endpoint)
+		case "file":
+			v.required("tracing.file_path", c.Tracing.FilePath)
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			v.addf("tracing.sample_ratio: must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+		}
+	}
+
+	if v.section("health", c.Health != nil) {
+		v.positiveDuration("health.check_timeout", c.Health.CheckTimeout)
+		// 端口为 0 表示消费者不提供健康检查端点
+		if c.Health.VoteConsumerPort != 0 {
+			v.port("health.vote_consumer_port", c.Health.VoteConsumerPort)
+		}
+		if c.Health.SyncConsumerPort != 0 {
+			v.port("health.sync_consumer_port", c.Health.SyncConsumerPort)
+		}
+	}
+
+	if v.section("cache", c.Cache != nil) {
+		v.positiveDuration("cache.post_ttl", c.Cache.PostTTL)
+		if c.Cache.PostTTLJitter < 0 {
+			v.addf("cache.post_ttl_jitter: must not be negative, got %s", c.Cache.PostTTLJitter)
+		}
+		v.positiveDuration("cache.post_negative_ttl", c.Cache.PostNegativeTTL)
+	}
+
+	if v.section("seed", c.Seed != nil) {
+		v.required("seed.admin_username", c.Seed.AdminUsername)
+	}
+
+	if v.section("bloom", c.Bloom != nil) {
+		v.positive("bloom.expected_posts", c.Bloom.ExpectedPosts)
+		if c.Bloom.FalsePositiveRate <= 0 || c.Bloom.FalsePositiveRate >= 1 {
+			v.addf("bloom.false_positive_rate: must be between 0 and 1 (exclusive), got %g", c.Bloom.FalsePositiveRate)
+		}
+		v.positive("bloom.rebuild_batch_size", int64(c.Bloom.RebuildBatchSize))
+	}
+
+	if v.section("local_cache", c.LocalCache != nil) {
+		v.required("local_cache.channel", c.LocalCache.Channel)
+		names := make([]string, 0, len(c.LocalCache.Caches))
+		for name := range c.LocalCache.Caches {
+			names = append(names, name)
+		}
+		slices.Sort(names) // 固定报错顺序
+		for _, name := range names {
+			entry := c.LocalCache.Caches[name]
+			v.positive("local_cache.caches."+name+".size", int64(entry.Size))
+			v.positiveDuration("local_cache.caches."+name+".ttl", entry.TTL)
+		}
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// validateReload 拒绝无法在运行中生效、替换后会导致状态不一致的变更
+func validateReload(old, new *Config) error {
+	if old == nil {
+		return nil
+	}
+	// 已签发的 Token 都用旧密钥签名，热更新密钥会让所有登录态失效，必须重启并有计划地轮换
+	if old.JWT.Secret != new.JWT.Secret {
+		return errors.New("jwt.secret cannot be changed without a restart")
+	}
+	return nil
+}
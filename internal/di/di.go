@@ -7,8 +7,7 @@ import (
 	"bluebell/internal/application/post"
 	"bluebell/internal/application/user"
 	"bluebell/internal/config"
-	"bluebell/internal/infrastructure/es"
-	"bluebell/internal/infrastructure/mq"
+	"bluebell/internal/domain"
 	mysqlrepo "bluebell/internal/infrastructure/persistence/mysql"
 	redisrepo "bluebell/internal/infrastructure/persistence/redis"
 )
@@ -24,13 +23,13 @@ type Services struct {
 func NewServices(
 	dbRepos *mysqlrepo.Repositories,
 	cacheRepos *redisrepo.Repositories,
-	publisher *mq.Publisher,
-	esClient *es.Client,
+	search domain.SearchBackend,
+	events domain.EventPublisher,
 	cfg *config.Config,
 ) *Services {
 	return &Services{
-		Post:      postsvc.NewPostService(dbRepos.Post, cacheRepos.PostCache, dbRepos.Vote, dbRepos.Remark, publisher, esClient),
+		Post:      postsvc.NewPostService(dbRepos.Post, cacheRepos.PostCache, cacheRepos.PostFilter, dbRepos.Vote, dbRepos.Remark, dbRepos.User, dbRepos.Community, events, dbRepos.Tx, search),
 		Community: communitysvc.NewCommunityService(dbRepos.Community, dbRepos.User),
-		User:      usersvc.NewUserService(dbRepos.User, cacheRepos.TokenCache, cfg),
+		User:      usersvc.NewUserService(dbRepos.User, cacheRepos.TokenCache, events, cfg),
 	}
 }
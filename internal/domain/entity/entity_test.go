@@ -77,3 +77,16 @@ func TestRemark_Validate(t *testing.T) {
 	r.Content = "  "
 	assert.Equal(t, ErrInvalidParam, r.Validate())
 }
+
+func TestIdempotencyRecord(t *testing.T) {
+	var r *IdempotencyRecord
+	assert.False(t, r.IsCompleted())
+	assert.False(t, r.MatchFingerprint("abc"))
+
+	r = &IdempotencyRecord{State: IdempotencyProcessing, Fingerprint: "abc"}
+	assert.False(t, r.IsCompleted())
+	assert.True(t, r.MatchFingerprint("abc"))
+	assert.False(t, r.MatchFingerprint("def"))
+	r.State = IdempotencyCompleted
+	assert.True(t, r.IsCompleted())
+}
@@ -47,4 +47,8 @@ var (
 	ErrForbidden         = errors.New("forbidden operation")
 	ErrRequestTimeout    = errors.New("request timeout")
 	ErrNotLogin          = errors.New("not logged in")
+
+	// 幂等相关错误
+	ErrIdempotencyInFlight = errors.New("a request with the same idempotency key is in progress")
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with a different request")
 )
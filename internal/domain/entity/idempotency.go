@@ -0,0 +1,27 @@
+package entity
+
+// 幂等记录状态常量
+const (
+	IdempotencyProcessing = "processing" // 首个请求处理中
+	IdempotencyCompleted  = "completed"  // 首个请求已完成，响应可重放
+)
+
+// IdempotencyRecord 幂等键对应的处理记录
+type IdempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"` // 请求指纹（方法 + 路径 + 请求体摘要）
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// IsCompleted 判断首个请求是否已经完成
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r != nil && r.State == IdempotencyCompleted
+}
+
+// MatchFingerprint 校验重放请求与首个请求是否一致
+// 核心业务规则：同一个幂等键只能用于同一个请求
+func (r *IdempotencyRecord) MatchFingerprint(fingerprint string) bool {
+	return r != nil && r.Fingerprint == fingerprint
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// 发件箱消息状态常量
+const (
+	OutboxStatusPending = 0 // 待投递
+	OutboxStatusSent    = 1 // 已投递（Broker 已确认）
+	OutboxStatusFailed  = 2 // 超过最大重试次数，需要人工介入
+)
+
+// OutboxMessage 发件箱消息
+// 与业务数据在同一个数据库事务中写入，由中继任务异步投递到消息队列
+type OutboxMessage struct {
+	ID         int64
+	Exchange   string
+	RoutingKey string
+	Payload    []byte
+	Headers    map[string]string // 投递时附带的消息头，如写入时的 trace context
+	Status     int8
+	Attempts   int
+	CreatedAt  time.Time
+}
@@ -16,6 +16,7 @@ import (
 const (
 	PostStatusPublished = 1  // 已发布
 	PostStatusDeleted   = 0  // 已删除（软删除）
+	PostStatusHidden    = 2  // 已隐藏（管理员操作）
 )
 
 // Post 帖子领域实体
@@ -16,6 +16,8 @@ type Vote struct {
 	PostID    int64
 	UserID    int64
 	Direction int8 // 1: 赞成, -1: 反对, 0: 取消
+	// Version 投票版本号，随投票时间递增；持久化时同一 (user, post) 只保留版本最新的投票
+	Version int64
 }
 
 // Validate 校验投票方向是否合法
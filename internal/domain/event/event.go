@@ -0,0 +1,128 @@
+// Package event 定义领域事件
+//
+// 领域事件由应用服务在业务操作完成时发布，经事件总线分发给本地处理器（搜索同步、通知、计数、审计等），
+// 需要跨进程处理的事件再由桥接处理器写入发件箱投递到 MQ
+package event
+
+import "time"
+
+// 事件名称常量，用于订阅
+const (
+	NamePostCreated    = "post.created"
+	NamePostDeleted    = "post.deleted"
+	NamePostEdited     = "post.edited"
+	NamePostHidden     = "post.hidden"
+	NamePostRestored   = "post.restored"
+	NameVoteCast       = "vote.cast"
+	NameRemarkAdded    = "remark.added"
+	NameRemarkEdited   = "remark.edited"
+	NameRemarkDeleted  = "remark.deleted"
+	NameUserRegistered = "user.registered"
+)
+
+// Event 领域事件
+type Event interface {
+	// EventName 事件名称
+	EventName() string
+}
+
+// PostSnapshot 帖子在事件发生时的完整状态，供下游（如搜索索引）直接使用，无需回查数据库
+type PostSnapshot struct {
+	PostID      int64
+	AuthorID    int64
+	CommunityID int64
+	Title       string
+	Content     string
+	Status      int8
+	CreatedAt   time.Time
+}
+
+// PostCreated 帖子已创建
+type PostCreated struct {
+	PostSnapshot
+}
+
+func (PostCreated) EventName() string { return NamePostCreated }
+
+// PostEdited 帖子内容已修改
+type PostEdited struct {
+	PostSnapshot
+}
+
+func (PostEdited) EventName() string { return NamePostEdited }
+
+// PostRestored 隐藏或删除的帖子已恢复
+type PostRestored struct {
+	PostSnapshot
+}
+
+func (PostRestored) EventName() string { return NamePostRestored }
+
+// PostDeleted 帖子已删除（软删除）
+type PostDeleted struct {
+	PostID      int64
+	AuthorID    int64
+	CommunityID int64
+}
+
+func (PostDeleted) EventName() string { return NamePostDeleted }
+
+// PostHidden 帖子已被管理员隐藏
+type PostHidden struct {
+	PostID      int64
+	CommunityID int64
+}
+
+func (PostHidden) EventName() string { return NamePostHidden }
+
+// VoteCast 用户已投票（含取消投票）
+type VoteCast struct {
+	PostID      int64
+	UserID      int64
+	CommunityID int64
+	Direction   int8
+	// VoteCount 投票后帖子的赞成票数，用于同步搜索索引的热度排序；小于 0 表示未知
+	VoteCount int64
+}
+
+func (VoteCast) EventName() string { return NameVoteCast }
+
+// RemarkSnapshot 评论在事件发生时的完整状态
+type RemarkSnapshot struct {
+	RemarkID    uint
+	PostID      int64
+	CommunityID int64 // 所属帖子的社区，便于搜索按社区过滤
+	AuthorID    int64
+	Content     string
+	CreatedAt   time.Time
+}
+
+// RemarkAdded 帖子新增评论
+type RemarkAdded struct {
+	RemarkSnapshot
+}
+
+func (RemarkAdded) EventName() string { return NameRemarkAdded }
+
+// RemarkEdited 评论内容已修改
+type RemarkEdited struct {
+	RemarkSnapshot
+}
+
+func (RemarkEdited) EventName() string { return NameRemarkEdited }
+
+// RemarkDeleted 评论已删除
+type RemarkDeleted struct {
+	RemarkID uint
+	PostID   int64
+}
+
+func (RemarkDeleted) EventName() string { return NameRemarkDeleted }
+
+// UserRegistered 用户已注册
+type UserRegistered struct {
+	UserID   int64
+	UserName string
+}
+
+func (UserRegistered) EventName() string { return NameUserRegistered }
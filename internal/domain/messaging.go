@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"bluebell/internal/domain/event"
+)
+
+// ========== 消息收发接口 ==========
+//
+// 与具体 Broker 无关的消息抽象：RabbitMQ 是其中一种实现，
+// 另有基于 channel 的进程内实现，用于单进程开发模式和集成测试
+
+// ErrBrokerDisconnected Broker 连接已断开，发布方应快速失败并稍后重试
+var ErrBrokerDisconnected = errors.New("message broker disconnected")
+
+// HeaderRequestID 消息头中透传的请求 ID，消费者据此把日志关联到发起请求
+const HeaderRequestID = "x-request-id"
+
+// Confirmation 异步投递的确认句柄
+type Confirmation interface {
+	// Wait 等待 Broker 确认，返回 nil 表示消息已被 Broker 接收；确认前连接断开时返回 ErrBrokerDisconnected
+	Wait(ctx context.Context) error
+}
+
+// Publisher 消息发布者
+type Publisher interface {
+	// Publish 以持久化方式投递消息到交换机，返回确认句柄；调用方可以先批量发出再统一等待确认
+	// 断线期间返回 ErrBrokerDisconnected
+	Publish(ctx context.Context, exchange, routingKey string, body []byte) (Confirmation, error)
+}
+
+// Delivery 一条待处理的消息，语义与 AMQP 的 ack/nack 一致
+type Delivery interface {
+	// Body 消息体
+	Body() []byte
+	// Headers 消息头中的字符串项，如发布方注入的 W3C trace context（traceparent）
+	Headers() map[string]string
+	// Ack 确认消息已处理；multiple 为 true 时一并确认同一订阅中此前所有未确认的消息
+	Ack(multiple bool) error
+	// Nack 拒绝消息；requeue 为 true 时立即重新入队，否则丢弃（需要保留失败消息时使用 Retry）
+	Nack(multiple, requeue bool) error
+	// Retry 处理失败，按实现的重试策略延迟重新投递，超过最大次数或遇到永久错误时进入死信；
+	// 原消息随之被确认，调用方无需再 Ack/Nack
+	Retry(ctx context.Context, cause error) error
+}
+
+// Subscriber 消息订阅者
+type Subscriber interface {
+	// Subscribe 订阅队列，prefetch 为未确认消息数上限
+	// 断线重连对调用方透明；返回的通道仅在 ctx 取消或订阅者关闭时关闭
+	Subscribe(ctx context.Context, queue string, prefetch int) (<-chan Delivery, error)
+}
+
+// ========== 领域事件接口 ==========
+
+// EventHandler 领域事件处理器
+// 处理器在发布方的 ctx 中同步执行：发布方处于事务中时，处理器的数据库写入（如发件箱）随同一事务提交或回滚
+type EventHandler func(ctx context.Context, e event.Event) error
+
+// EventPublisher 领域事件发布者，供应用服务使用
+type EventPublisher interface {
+	// Publish 发布事件，依次分发给所有订阅的处理器，返回各处理器错误的合并结果
+	Publish(ctx context.Context, events ...event.Event) error
+}
+
+// EventBus 领域事件总线
+type EventBus interface {
+	EventPublisher
+	// Subscribe 订阅指定名称的事件
+	Subscribe(name string, handler EventHandler)
+}
+
+// permanentError 不可重试的错误（如消息格式错误），直接进入死信
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误为不可重试
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否被标记为不可重试
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
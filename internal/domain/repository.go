@@ -22,12 +22,26 @@ type PostCacheRepository interface {
 	GetCommunityPostIDsInOrder(ctx context.Context, communityID int64, orderKey string, page, size int64) ([]string, error)
 	// VoteForPost 为帖子投票
 	VoteForPost(ctx context.Context, userID, postID, communityID string, value float64) error
+	// GetUserVote 获取用户当前对帖子的投票值，未投票时返回 0
+	GetUserVote(ctx context.Context, userID, postID string) (float64, error)
 	// GetPostsVoteData 批量获取多个帖子的投票数（赞成票数）
 	GetPostsVoteData(ctx context.Context, ids []string) ([]int64, error)
-	// DeletePost 删除帖子时清理 Redis 缓存（ZSet、Hash、投票记录）
+	// DeletePost 删除帖子时清理 Redis 缓存（ZSet、Hash、投票记录、详情缓存）
 	DeletePost(ctx context.Context, postID, communityID int64) error
 	// GetPostCommunityID 从 Redis 缓存中获取帖子的社区 ID
 	GetPostCommunityID(ctx context.Context, postID int64) (int64, error)
+	// GetRelatedPostIDs 读取缓存的相关帖子 ID 列表，未命中时 ok 为 false
+	GetRelatedPostIDs(ctx context.Context, postID int64, scope string) (ids []string, ok bool, err error)
+	// SetRelatedPostIDs 缓存相关帖子 ID 列表
+	SetRelatedPostIDs(ctx context.Context, postID int64, scope string, ids []string, ttl time.Duration) error
+}
+
+// PostFilter 帖子 ID 存在性过滤器（布隆过滤器），在查库前拦截不存在的帖子 ID
+type PostFilter interface {
+	// MightExist 帖子可能存在时返回 true，返回 false 时一定不存在；出错时返回 true 和错误，调用方应照常查库
+	MightExist(ctx context.Context, postID int64) (bool, error)
+	// Add 记录新建的帖子 ID；失败时由实现负责让所有副本放行查询并重建，调用方记录日志后照常继续
+	Add(ctx context.Context, postID int64) error
 }
 
 // UserTokenCacheRepository 用户 Token 缓存仓储接口（Redis）
@@ -42,14 +56,35 @@ type UserTokenCacheRepository interface {
 	DeleteUserToken(ctx context.Context, userID int64) error
 }
 
+// IdempotencyCacheRepository 幂等键缓存仓储接口（Redis）
+type IdempotencyCacheRepository interface {
+	// Acquire 为幂等键抢占处理权，返回 false 表示已有同键请求在处理或已完成
+	Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (bool, error)
+	// Get 获取幂等键对应的记录，不存在时返回 nil
+	Get(ctx context.Context, key string) (*entity.IdempotencyRecord, error)
+	// Complete 保存首次请求的响应，供后续重放
+	Complete(ctx context.Context, key string, record *entity.IdempotencyRecord, ttl time.Duration) error
+	// Release 释放处理权（首次请求失败时调用，允许客户端重试）
+	Release(ctx context.Context, key string) error
+}
+
 // ========== 数据库层仓储接口 ==========
 
+// TransactionManager 事务管理器接口
+// fn 内部通过 ctx 调用的所有数据库仓储共用同一个事务，fn 返回 error 时整体回滚
+type TransactionManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // PostRepository 帖子数据库仓储接口（MySQL）
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *entity.Post) error
 	GetPostByID(ctx context.Context, pid int64) (*entity.Post, error)
 	GetPostListByIDsWithPreload(ctx context.Context, ids []string) ([]*entity.Post, error)
 	DeletePostByAuthor(ctx context.Context, postID, authorID int64) error
+	// ScanPosts 按主键顺序分批遍历帖子（含各种状态），updatedSince 非零时只遍历此后修改过的帖子
+	// 每批调用一次 fn，fn 返回错误时停止遍历；用于搜索索引全量重建
+	ScanPosts(ctx context.Context, updatedSince time.Time, batchSize int, fn func(posts []*entity.Post) error) error
 }
 
 // CommunityRepository 社区数据库仓储接口
@@ -73,6 +108,9 @@ type UserRepository interface {
 // VoteRepository 投票数据库仓储接口
 type VoteRepository interface {
 	SaveVote(ctx context.Context, userID, postID int64, direction int8) error
+	// SaveVotes 批量保存投票，单条多行 UPSERT；调用方需保证同一 (user, post) 只出现一次
+	// 已有记录只在投票版本不低于当前版本时更新，乱序到达的旧投票被忽略
+	SaveVotes(ctx context.Context, votes []*entity.Vote) error
 }
 
 // RemarkRepository 评论数据库仓储接口
@@ -82,3 +120,21 @@ type RemarkRepository interface {
 	DeleteRemarkByID(ctx context.Context, remarkID uint) error
 	DeleteRemarksByPostID(ctx context.Context, postID int64) error
 }
+
+// OutboxRepository 发件箱仓储接口（MySQL）
+type OutboxRepository interface {
+	// Add 写入一条待投递消息，应与业务数据处于同一个事务
+	Add(ctx context.Context, msg *entity.OutboxMessage) error
+	// FetchPending 按写入顺序锁定并获取一批未被领取（或租约已到期）的待投递消息，跳过其他实例已锁定的行
+	FetchPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error)
+	// Lease 领取消息直到 until，应与 FetchPending 处于同一个事务
+	Lease(ctx context.Context, ids []int64, until time.Time) error
+	// Release 释放租约，消息在下一轮立即可被领取
+	Release(ctx context.Context, ids []int64) error
+	// MarkSent 将消息标记为已投递
+	MarkSent(ctx context.Context, ids []int64) error
+	// MarkFailed 记录一次投递失败并释放租约，达到 maxAttempts 后不再重试
+	MarkFailed(ctx context.Context, id int64, reason string, maxAttempts int) error
+	// DeleteSent 删除 before 之前投递成功的消息，单次最多 limit 条，返回删除的条数
+	DeleteSent(ctx context.Context, before time.Time, limit int) (int64, error)
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ========== 全文搜索接口 ==========
+//
+// 与具体搜索引擎无关的搜索抽象：Elasticsearch 是其中一种实现，
+// 另有基于本地文件的嵌入式实现，用于没有 ES 的开发机和 CI 环境
+
+// 搜索范围
+const (
+	SearchTypePost   = "post"   // 只搜帖子
+	SearchTypeRemark = "remark" // 只搜评论
+	SearchTypeAll    = "all"    // 帖子和评论一起搜
+)
+
+// 搜索排序方式
+const (
+	SearchSortRelevance = "relevance" // 按相关度，相同时按时间倒序
+	SearchSortNew       = "new"       // 按创建时间倒序
+	SearchSortTop       = "top"       // 按赞成票数倒序，相同时按时间倒序
+)
+
+// SearchQuery 搜索条件
+type SearchQuery struct {
+	Keyword      string
+	Page         int       // 当前页码（从 1 开始）
+	PageSize     int       // 每页条数（最大 50）
+	CommunityIDs []int64   // 按社区过滤（任一匹配），为空不过滤；不影响社区分面统计
+	AuthorID     int64     // 按作者过滤，0 不过滤
+	CreatedFrom  time.Time // 创建时间下限（含），零值不限制
+	CreatedTo    time.Time // 创建时间上限（不含），零值不限制
+	Sort         string    // SearchSortRelevance（默认）| SearchSortNew | SearchSortTop
+	Type         string    // SearchTypePost（默认）| SearchTypeRemark | SearchTypeAll
+}
+
+// SearchHit 单条命中
+// 评论命中时 RemarkID 有值，PostID 为所属帖子，Title 为空
+type SearchHit struct {
+	Type             string // SearchTypePost | SearchTypeRemark
+	RemarkID         string
+	PostID           string
+	AuthorID         int64
+	CommunityID      int64
+	Title            string
+	Content          string
+	Status           int8
+	VoteCount        int64
+	CreatedAt        time.Time
+	HighlightTitle   []string // 标题高亮片段
+	HighlightContent []string // 内容高亮片段
+}
+
+// CommunityFacet 单个社区的命中数
+type CommunityFacet struct {
+	CommunityID int64
+	Count       int64
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Total       int64
+	Page        int
+	PageSize    int
+	Hits        []SearchHit
+	Communities []CommunityFacet // 社区分面，按命中数倒序
+	Suggestion  string           // 命中过少时的纠错建议，无建议时为空
+}
+
+// SearchBackend 搜索查询端
+type SearchBackend interface {
+	// Search 全文搜索，支持过滤、排序、高亮和社区分面
+	Search(ctx context.Context, q *SearchQuery) (*SearchResult, error)
+	// Suggest 按前缀联想帖子标题
+	Suggest(ctx context.Context, prefix string, size int) ([]string, error)
+	// RelatedPostIDs 查找与指定帖子内容相近的已发布帖子，communityID 大于 0 时限定社区，结果不含帖子本身
+	RelatedPostIDs(ctx context.Context, postID string, communityID int64, size int) ([]string, error)
+}
+
+// PostDocument 帖子在搜索索引中的文档
+type PostDocument struct {
+	PostID      string
+	AuthorID    int64
+	CommunityID int64
+	Title       string
+	Content     string
+	Status      int8
+	VoteCount   int64 // 仅在新建文档时写入，已索引的文档票数由 UpdatePostVotes 维护
+	CreatedAt   time.Time
+}
+
+// RemarkDocument 评论在搜索索引中的文档
+type RemarkDocument struct {
+	RemarkID    string
+	PostID      string
+	AuthorID    int64
+	CommunityID int64
+	Content     string
+	CreatedAt   time.Time
+}
+
+// SearchIndexer 搜索索引写入端，由搜索同步消费者调用
+// 所有操作都应是幂等的：删除不存在的文档、更新未索引的文档均视为成功
+type SearchIndexer interface {
+	// IndexPost 写入帖子文档，文档已存在时保留索引中的票数
+	IndexPost(ctx context.Context, doc *PostDocument) error
+	DeletePost(ctx context.Context, postID string) error
+	// UpdatePostVotes 只更新帖子的票数
+	UpdatePostVotes(ctx context.Context, postID string, voteCount int64) error
+	IndexRemark(ctx context.Context, doc *RemarkDocument) error
+	DeleteRemark(ctx context.Context, remarkID string) error
+	// DeletePostRemarks 删除帖子下的全部评论文档，用于帖子被删除后清理
+	DeletePostRemarks(ctx context.Context, postID string) error
+}
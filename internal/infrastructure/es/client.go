@@ -2,8 +2,11 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"strings"
 
 	"bluebell/internal/config"
 
@@ -11,8 +14,13 @@ import (
 	"go.uber.org/zap"
 )
 
+// IndexPost 帖子索引的别名，读写一律经由别名访问。
+// 实际数据存放在 post_v{n} 版本索引中，重建索引时原子切换别名实现零停机
 const IndexPost = "post"
 
+// IndexRemark 评论索引的别名，实际数据存放在 remark_v{n} 中
+const IndexRemark = "remark"
+
 // Client wraps the Elasticsearch client.
 type Client struct {
 	es *elasticsearch.Client
@@ -53,6 +61,27 @@ func (c *Client) ES() *elasticsearch.Client {
 	return c.es
 }
 
+// ClusterHealth 查询集群健康状态，返回 green / yellow / red
+func (c *Client) ClusterHealth(ctx context.Context) (string, error) {
+	res, err := c.es.Cluster.Health(c.es.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("ES cluster health failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return "", fmt.Errorf("ES cluster health error: %s", string(body))
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return "", fmt.Errorf("decode ES cluster health failed: %w", err)
+	}
+	return health.Status, nil
+}
+
 // DeleteDocument deletes a document from the specified index by ID
 func (c *Client) DeleteDocument(ctx context.Context, index, docID string) error {
 	res, err := c.es.Delete(index, docID, c.es.Delete.WithContext(ctx))
@@ -61,6 +90,10 @@ func (c *Client) DeleteDocument(ctx context.Context, index, docID string) error
 	}
 	defer res.Body.Close()
 
+	// 文档不存在视为删除成功，保证重复投递的删除消息是幂等的
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
 	if res.IsError() {
 		body, _ := io.ReadAll(res.Body)
 		return fmt.Errorf("ES delete document error: %s", string(body))
@@ -68,6 +101,67 @@ func (c *Client) DeleteDocument(ctx context.Context, index, docID string) error
 	return nil
 }
 
+// DeleteByQuery deletes all documents in the index matching the query.
+// 版本冲突（文档在删除期间被并发修改）时继续执行，索引不存在视为删除成功
+func (c *Client) DeleteByQuery(ctx context.Context, index string, query io.Reader) error {
+	res, err := c.es.DeleteByQuery([]string{index}, query,
+		c.es.DeleteByQuery.WithContext(ctx),
+		c.es.DeleteByQuery.WithConflicts("proceed"),
+	)
+	if err != nil {
+		return fmt.Errorf("ES delete by query failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("ES delete by query error: %s", string(body))
+	}
+	return nil
+}
+
+// UpdateVoteCount partially updates the vote count of a post document.
+// 文档尚未索引（帖子已删除或创建消息尚未消费）时忽略，票数由下次投票或重建索引补齐
+func (c *Client) UpdateVoteCount(ctx context.Context, index, docID string, voteCount int64) error {
+	body := fmt.Sprintf(`{"doc":{"vote_count":%d}}`, voteCount)
+	res, err := c.es.Update(index, docID, strings.NewReader(body), c.es.Update.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("ES update document failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.IsError() {
+		respBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("ES update document error: %s", string(respBody))
+	}
+	return nil
+}
+
+// UpsertDocument applies a partial update with an upsert body to the document.
+// 与票数更新并发时可能出现版本冲突，由 ES 在服务端重试
+func (c *Client) UpsertDocument(ctx context.Context, index, docID string, body io.Reader) error {
+	res, err := c.es.Update(index, docID, body,
+		c.es.Update.WithContext(ctx),
+		c.es.Update.WithRetryOnConflict(3),
+	)
+	if err != nil {
+		return fmt.Errorf("ES upsert document failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		respBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("ES upsert document error: %s", string(respBody))
+	}
+	return nil
+}
+
 // IndexDocument indexes a document into the specified index
 func (c *Client) IndexDocument(ctx context.Context, index, docID string, body io.Reader) error {
 	res, err := c.es.Index(
@@ -0,0 +1,94 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bluebell/internal/domain"
+)
+
+// postSource 帖子文档在索引中的 _source
+func postSource(doc *domain.PostDocument) map[string]interface{} {
+	return map[string]interface{}{
+		"post_id":      doc.PostID,
+		"author_id":    doc.AuthorID,
+		"community_id": doc.CommunityID,
+		"post_title":   doc.Title,
+		"content":      doc.Content,
+		"status":       doc.Status,
+		"vote_count":   doc.VoteCount,
+		"created_at":   doc.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// IndexPost 写入帖子文档，实现 domain.SearchIndexer
+// 帖子消息不含实时票数，已存在的文档只做局部更新、保留 vote_count，不存在时以完整文档创建
+func (c *Client) IndexPost(ctx context.Context, doc *domain.PostDocument) error {
+	full := postSource(doc)
+	partial := make(map[string]interface{}, len(full))
+	for k, v := range full {
+		if k != "vote_count" {
+			partial[k] = v
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"doc":    partial,
+		"upsert": full,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal post document failed: %w", err)
+	}
+	return c.UpsertDocument(ctx, IndexPost, doc.PostID, bytes.NewReader(body))
+}
+
+// DeletePost 删除帖子文档
+func (c *Client) DeletePost(ctx context.Context, postID string) error {
+	return c.DeleteDocument(ctx, IndexPost, postID)
+}
+
+// UpdatePostVotes 更新帖子文档的票数
+func (c *Client) UpdatePostVotes(ctx context.Context, postID string, voteCount int64) error {
+	return c.UpdateVoteCount(ctx, IndexPost, postID, voteCount)
+}
+
+// IndexRemark 写入评论文档
+func (c *Client) IndexRemark(ctx context.Context, doc *domain.RemarkDocument) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"remark_id":    doc.RemarkID,
+		"post_id":      doc.PostID,
+		"author_id":    doc.AuthorID,
+		"community_id": doc.CommunityID,
+		"content":      doc.Content,
+		"created_at":   doc.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal remark document failed: %w", err)
+	}
+	return c.IndexDocument(ctx, IndexRemark, doc.RemarkID, bytes.NewReader(body))
+}
+
+// DeleteRemark 删除评论文档
+func (c *Client) DeleteRemark(ctx context.Context, remarkID string) error {
+	return c.DeleteDocument(ctx, IndexRemark, remarkID)
+}
+
+// DeletePostRemarks 按 post_id 删除帖子下的全部评论文档
+func (c *Client) DeletePostRemarks(ctx context.Context, postID string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"post_id": postID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal delete remarks query failed: %w", err)
+	}
+	return c.DeleteByQuery(ctx, IndexRemark, bytes.NewReader(body))
+}
+
+var (
+	_ domain.SearchBackend = (*Client)(nil)
+	_ domain.SearchIndexer = (*Client)(nil)
+)
@@ -0,0 +1,58 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+func TestClient_IndexPostKeepsVoteCount(t *testing.T) {
+	var method, path string
+	var body struct {
+		Doc    map[string]interface{} `json:"doc"`
+		Upsert map[string]interface{} `json:"upsert"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request body: %v", err)
+		}
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"updated"}`))
+	}))
+	defer srv.Close()
+
+	es, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{es: es}
+	err = c.IndexPost(context.Background(), &domain.PostDocument{
+		PostID: "1", Title: "edited", VoteCount: 0, CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("IndexPost() error = %v", err)
+	}
+
+	// 已存在的文档只局部更新，不能带上 vote_count 把票数清零；不存在时以完整文档创建
+	if method != http.MethodPost || path != "/"+IndexPost+"/_update/1" {
+		t.Errorf("request = %s %s, want POST /%s/_update/1", method, path, IndexPost)
+	}
+	if _, ok := body.Doc["vote_count"]; ok {
+		t.Errorf("partial doc = %v, must not contain vote_count", body.Doc)
+	}
+	if body.Doc["post_title"] != "edited" {
+		t.Errorf("partial doc post_title = %v, want edited", body.Doc["post_title"])
+	}
+	if _, ok := body.Upsert["vote_count"]; !ok {
+		t.Errorf("upsert doc = %v, want vote_count for new documents", body.Upsert)
+	}
+}
@@ -3,6 +3,7 @@ package es
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -11,6 +12,7 @@ import (
 
 // PostMapping defines the Elasticsearch index mapping for posts.
 // Uses IK analyzer for Chinese full-text search on title and content.
+// post_title.suggest is a completion field backing the search box autocomplete.
 const PostMapping = `{
     "mappings": {
         "properties": {
@@ -20,7 +22,10 @@ const PostMapping = `{
             "post_title": {
                 "type": "text",
                 "analyzer": "ik_max_word",
-                "search_analyzer": "ik_smart"
+                "search_analyzer": "ik_smart",
+                "fields": {
+                    "suggest": { "type": "completion" }
+                }
             },
             "content": {
                 "type": "text",
@@ -28,6 +33,30 @@ const PostMapping = `{
                 "search_analyzer": "ik_smart"
             },
             "status": { "type": "integer" },
+            "vote_count": { "type": "long" },
+            "created_at": { "type": "date" }
+        }
+    },
+    "settings": {
+        "number_of_shards": 1,
+        "number_of_replicas": 0
+    }
+}`
+
+// RemarkMapping defines the Elasticsearch index mapping for remarks.
+// Uses the same IK analyzers as PostMapping so posts and remarks can be searched together.
+const RemarkMapping = `{
+    "mappings": {
+        "properties": {
+            "remark_id": { "type": "keyword" },
+            "post_id": { "type": "keyword" },
+            "author_id": { "type": "long" },
+            "community_id": { "type": "long" },
+            "content": {
+                "type": "text",
+                "analyzer": "ik_max_word",
+                "search_analyzer": "ik_smart"
+            },
             "created_at": { "type": "date" }
         }
     },
@@ -37,34 +66,73 @@ const PostMapping = `{
     }
 }`
 
-// CreatePostIndex creates the post index if it does not already exist.
+// CreatePostIndex creates the first versioned post index behind the alias if neither exists.
 // Called by: cmd/bluebell/main.go (line 123: esClient.CreatePostIndex(ctx))
 func (c *Client) CreatePostIndex(ctx context.Context) error {
+	return c.ensureAliasedIndex(ctx, IndexPost, PostMapping)
+}
+
+// CreateRemarkIndex creates the first versioned remark index behind the alias if neither exists.
+func (c *Client) CreateRemarkIndex(ctx context.Context) error {
+	return c.ensureAliasedIndex(ctx, IndexRemark, RemarkMapping)
+}
+
+// ensureAliasedIndex 别名不存在时创建 {alias}_v1 并挂上别名
+func (c *Client) ensureAliasedIndex(ctx context.Context, alias, mapping string) error {
 	// 检查 index 是否存在谁拿到 *esapi.Response，谁负责 Body.Close()。
-	exists, err := c.es.Indices.Exists([]string{IndexPost})
+	// 别名同样会命中：存在同名别名或（旧版本遗留的）具体索引时均无需创建
+	exists, err := c.es.Indices.Exists([]string{alias}, c.es.Indices.Exists.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("check index existence failed: %w", err)
 	}
 	defer exists.Body.Close()
 
 	if exists.StatusCode == 200 {
-		zap.L().Info("ES index already exists", zap.String("index", IndexPost))
+		zap.L().Info("ES index already exists", zap.String("index", alias))
 		return nil
 	}
 
-	// 创建 index
-	req := bytes.NewReader([]byte(PostMapping))
-	res, err := c.es.Indices.Create(IndexPost, c.es.Indices.Create.WithBody(req))
+	index := alias + "_v1"
+	body, err := indexBody(mapping, func(m map[string]interface{}) {
+		m["aliases"] = map[string]interface{}{alias: map[string]interface{}{}}
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.createIndex(ctx, index, body); err != nil {
+		return err
+	}
+
+	zap.L().Info("ES index created", zap.String("index", index), zap.String("alias", alias))
+	return nil
+}
+
+// indexBody 以 mapping 为基础生成建索引请求体，tweak 可在序列化前修改请求体
+func indexBody(mapping string, tweak func(m map[string]interface{})) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(mapping), &m); err != nil {
+		return nil, fmt.Errorf("parse index mapping failed: %w", err)
+	}
+	if tweak != nil {
+		tweak(m)
+	}
+	return json.Marshal(m)
+}
+
+// createIndex 使用给定请求体创建索引
+func (c *Client) createIndex(ctx context.Context, index string, body []byte) error {
+	res, err := c.es.Indices.Create(index,
+		c.es.Indices.Create.WithContext(ctx),
+		c.es.Indices.Create.WithBody(bytes.NewReader(body)),
+	)
 	if err != nil {
 		return fmt.Errorf("create index failed: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		body, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("create index error: %s", string(body))
+		respBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("create index error: %s", string(respBody))
 	}
-
-	zap.L().Info("ES index created", zap.String("index", IndexPost))
 	return nil
 }
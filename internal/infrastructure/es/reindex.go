@@ -0,0 +1,403 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// postIndexPrefix 帖子版本索引名前缀，完整索引名为 post_v{n}
+const postIndexPrefix = IndexPost + "_v"
+
+// catchUpSkew 增量补齐时向前多取的时间，容忍应用与 MySQL 之间的时钟偏差
+const catchUpSkew = time.Minute
+
+// ReindexOptions 全量重建索引参数
+type ReindexOptions struct {
+	BatchSize int  // 每批从 MySQL 读取并写入 ES 的帖子数
+	KeepOld   bool // 切换别名后保留旧索引，便于回滚
+	// VoteCounts 批量读取帖子赞成票数，返回值与 ids 一一对应；为空时不写入票数
+	VoteCounts func(ctx context.Context, ids []string) ([]int64, error)
+}
+
+// ReindexResult 全量重建索引结果
+type ReindexResult struct {
+	Index      string   // 新建的版本索引
+	OldIndices []string // 切换前别名指向的索引
+	Scanned    int      // 全量阶段扫描的帖子数
+	Indexed    int      // 全量阶段写入的已发布帖子数
+	CaughtUp   int      // 切换别名后增量补齐的帖子数
+}
+
+// Reindex 从 MySQL 全量重建帖子索引并零停机切换别名
+//  1. 新建 post_v{n}，导入期间关闭自动刷新
+//  2. 按主键分批扫描帖子，通过 _bulk 写入已发布的帖子
+//  3. 恢复刷新后，在一次 _aliases 请求中把别名从旧索引原子切换到新索引
+//  4. 补齐导入期间被修改的帖子（这些修改已由同步消费者写入旧索引）
+//  5. 删除旧索引
+func (c *Client) Reindex(ctx context.Context, posts domain.PostRepository, opts ReindexOptions) (*ReindexResult, error) {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 500
+	}
+
+	oldIndices, legacy, err := c.aliasTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := c.nextIndexVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReindexResult{Index: versionedIndex(version), OldIndices: oldIndices}
+	body, err := indexBody(PostMapping, func(m map[string]interface{}) {
+		settings, _ := m["settings"].(map[string]interface{})
+		if settings == nil {
+			settings = map[string]interface{}{}
+			m["settings"] = settings
+		}
+		settings["refresh_interval"] = "-1"
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.createIndex(ctx, result.Index, body); err != nil {
+		return nil, err
+	}
+	zap.L().Info("reindex started", zap.String("index", result.Index), zap.Strings("old_indices", oldIndices))
+
+	// 全量导入
+	startedAt := time.Now()
+	err = posts.ScanPosts(ctx, time.Time{}, opts.BatchSize, func(batch []*entity.Post) error {
+		published := make([]*entity.Post, 0, len(batch))
+		for _, p := range batch {
+			if p.IsPublished() {
+				published = append(published, p)
+			}
+		}
+		if err := c.bulkSync(ctx, result.Index, published, nil, opts.VoteCounts); err != nil {
+			return err
+		}
+		result.Scanned += len(batch)
+		result.Indexed += len(published)
+		zap.L().Info("reindex progress",
+			zap.String("index", result.Index),
+			zap.Int("scanned", result.Scanned),
+			zap.Int("indexed", result.Indexed),
+			zap.Duration("elapsed", time.Since(startedAt)),
+		)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("reindex into %s failed: %w", result.Index, err)
+	}
+
+	// 恢复默认刷新间隔并立即刷新，使切换后的首次查询即可见全部数据
+	if err := c.putIndexSettings(ctx, result.Index, `{"index":{"refresh_interval":null}}`); err != nil {
+		return result, err
+	}
+	if err := c.refresh(ctx, result.Index); err != nil {
+		return result, err
+	}
+
+	if err := c.swapAlias(ctx, result.Index, oldIndices, legacy); err != nil {
+		return result, err
+	}
+	zap.L().Info("reindex alias switched", zap.String("alias", IndexPost), zap.String("index", result.Index))
+
+	// 增量补齐：导入开始后修改过的帖子按当前状态写入或删除
+	err = posts.ScanPosts(ctx, startedAt.Add(-catchUpSkew), opts.BatchSize, func(batch []*entity.Post) error {
+		var upserts, deletes []*entity.Post
+		for _, p := range batch {
+			if p.IsPublished() {
+				upserts = append(upserts, p)
+			} else {
+				deletes = append(deletes, p)
+			}
+		}
+		result.CaughtUp += len(batch)
+		return c.bulkSync(ctx, result.Index, upserts, deletes, opts.VoteCounts)
+	})
+	if err != nil {
+		return result, fmt.Errorf("reindex catch-up failed: %w", err)
+	}
+
+	// 旧版本遗留的具体索引已在切换别名时通过 remove_index 删除
+	if !opts.KeepOld && !legacy {
+		for _, index := range oldIndices {
+			if err := c.deleteIndex(ctx, index); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	zap.L().Info("reindex finished",
+		zap.String("index", result.Index),
+		zap.Int("indexed", result.Indexed),
+		zap.Int("caught_up", result.CaughtUp),
+		zap.Duration("elapsed", time.Since(startedAt)),
+	)
+	return result, nil
+}
+
+// versionedIndex 返回指定版本的帖子索引名
+func versionedIndex(version int) string {
+	return postIndexPrefix + strconv.Itoa(version)
+}
+
+// aliasTargets 返回别名当前指向的索引
+// legacy 为 true 表示 post 是早期直接创建的具体索引而非别名，此时 indices 为 [post]
+func (c *Client) aliasTargets(ctx context.Context) (indices []string, legacy bool, err error) {
+	res, err := c.es.Indices.GetAlias(
+		c.es.Indices.GetAlias.WithContext(ctx),
+		c.es.Indices.GetAlias.WithName(IndexPost),
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("get alias failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		exists, err := c.es.Indices.Exists([]string{IndexPost}, c.es.Indices.Exists.WithContext(ctx))
+		if err != nil {
+			return nil, false, fmt.Errorf("check index existence failed: %w", err)
+		}
+		defer exists.Body.Close()
+		if exists.StatusCode == http.StatusOK {
+			return []string{IndexPost}, true, nil
+		}
+		return nil, false, nil
+	}
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return nil, false, fmt.Errorf("get alias error: %s", string(body))
+	}
+
+	var aliases map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+		return nil, false, fmt.Errorf("decode alias response failed: %w", err)
+	}
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	return indices, false, nil
+}
+
+// nextIndexVersion 返回比现有 post_v{n} 索引都大的下一个版本号
+func (c *Client) nextIndexVersion(ctx context.Context) (int, error) {
+	res, err := c.es.Indices.Get([]string{postIndexPrefix + "*"},
+		c.es.Indices.Get.WithContext(ctx),
+		c.es.Indices.Get.WithAllowNoIndices(true),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("list post indices failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return 0, fmt.Errorf("list post indices error: %s", string(body))
+	}
+
+	var indices map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return 0, fmt.Errorf("decode indices response failed: %w", err)
+	}
+	latest := 0
+	for index := range indices {
+		if n, err := strconv.Atoi(strings.TrimPrefix(index, postIndexPrefix)); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return latest + 1, nil
+}
+
+// postDocument 帖子在索引中的文档，与搜索同步消费者写入的文档保持一致
+func postDocument(p *entity.Post, voteCount int64) map[string]interface{} {
+	return postSource(&domain.PostDocument{
+		PostID:      p.PostID,
+		AuthorID:    p.AuthorID,
+		CommunityID: p.CommunityID,
+		Title:       p.PostTitle,
+		Content:     p.Content,
+		Status:      p.Status,
+		VoteCount:   voteCount,
+		CreatedAt:   p.CreatedAt,
+	})
+}
+
+// bulkSync 通过一次 _bulk 请求写入 upserts 并删除 deletes
+func (c *Client) bulkSync(ctx context.Context, index string, upserts, deletes []*entity.Post,
+	voteCounts func(ctx context.Context, ids []string) ([]int64, error)) error {
+	if len(upserts) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	counts := make([]int64, len(upserts))
+	if voteCounts != nil && len(upserts) > 0 {
+		ids := make([]string, len(upserts))
+		for i, p := range upserts {
+			ids[i] = p.PostID
+		}
+		got, err := voteCounts(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("get vote counts failed: %w", err)
+		}
+		copy(counts, got)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i, p := range upserts {
+		if err := enc.Encode(map[string]interface{}{"index": map[string]string{"_id": p.PostID}}); err != nil {
+			return fmt.Errorf("encode bulk action failed: %w", err)
+		}
+		if err := enc.Encode(postDocument(p, counts[i])); err != nil {
+			return fmt.Errorf("encode bulk document failed: %w", err)
+		}
+	}
+	for _, p := range deletes {
+		if err := enc.Encode(map[string]interface{}{"delete": map[string]string{"_id": p.PostID}}); err != nil {
+			return fmt.Errorf("encode bulk action failed: %w", err)
+		}
+	}
+
+	res, err := c.es.Bulk(bytes.NewReader(buf.Bytes()),
+		c.es.Bulk.WithContext(ctx),
+		c.es.Bulk.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("ES bulk request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("ES bulk error: %s", string(body))
+	}
+
+	var bulkRes struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&bulkRes); err != nil {
+		return fmt.Errorf("decode bulk response failed: %w", err)
+	}
+	if !bulkRes.Errors {
+		return nil
+	}
+	// 删除不存在的文档返回 not_found 但不带 error 字段，不视为失败
+	for _, item := range bulkRes.Items {
+		for action, r := range item {
+			if len(r.Error) > 0 {
+				return fmt.Errorf("ES bulk %s failed (id: %s): %s", action, r.ID, string(r.Error))
+			}
+		}
+	}
+	return nil
+}
+
+// swapAlias 在一次 _aliases 请求中把别名切换到新索引，切换是原子的
+func (c *Client) swapAlias(ctx context.Context, index string, oldIndices []string, legacy bool) error {
+	var actions []map[string]interface{}
+	if legacy {
+		// 别名不能与现有索引同名，需在同一请求中删除旧的具体索引
+		actions = append(actions, map[string]interface{}{
+			"remove_index": map[string]string{"index": IndexPost},
+		})
+	} else {
+		for _, old := range oldIndices {
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]string{"index": old, "alias": IndexPost},
+			})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]string{"index": index, "alias": IndexPost},
+	})
+
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("marshal alias actions failed: %w", err)
+	}
+	res, err := c.es.Indices.UpdateAliases(bytes.NewReader(body), c.es.Indices.UpdateAliases.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("update aliases failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		respBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("update aliases error: %s", string(respBody))
+	}
+	return nil
+}
+
+// putIndexSettings 更新索引的动态设置
+func (c *Client) putIndexSettings(ctx context.Context, index, settings string) error {
+	res, err := c.es.Indices.PutSettings(strings.NewReader(settings),
+		c.es.Indices.PutSettings.WithContext(ctx),
+		c.es.Indices.PutSettings.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("put index settings failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("put index settings error: %s", string(body))
+	}
+	return nil
+}
+
+// refresh 刷新索引，使已写入的文档可被搜索
+func (c *Client) refresh(ctx context.Context, index string) error {
+	res, err := c.es.Indices.Refresh(
+		c.es.Indices.Refresh.WithContext(ctx),
+		c.es.Indices.Refresh.WithIndex(index),
+	)
+	if err != nil {
+		return fmt.Errorf("refresh index failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("refresh index error: %s", string(body))
+	}
+	return nil
+}
+
+// deleteIndex 删除索引，索引不存在视为成功
+func (c *Client) deleteIndex(ctx context.Context, index string) error {
+	res, err := c.es.Indices.Delete([]string{index}, c.es.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("delete index failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.IsError() {
+		body, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("delete index error: %s", string(body))
+	}
+	zap.L().Info("ES index deleted", zap.String("index", index))
+	return nil
+}
@@ -0,0 +1,66 @@
+package es
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// RelatedPostIDs 使用 more_like_this 查找与指定帖子内容相近的已发布帖子
+// communityID 大于 0 时只在同一社区内查找；结果不包含帖子本身
+func (c *Client) RelatedPostIDs(ctx context.Context, postID string, communityID int64, size int) ([]string, error) {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"status": 1}},
+	}
+	if communityID > 0 {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"community_id": communityID},
+		})
+	}
+
+	query := map[string]interface{}{
+		"size":    size,
+		"_source": []string{"post_id"},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"more_like_this": map[string]interface{}{
+						"fields": []string{"post_title", "content"},
+						"like": []map[string]interface{}{
+							{"_index": IndexPost, "_id": postID},
+						},
+						// 帖子普遍较短，放宽词频要求，否则大部分帖子提取不出关键词
+						"min_term_freq":   1,
+						"min_doc_freq":    1,
+						"max_query_terms": 25,
+					},
+				},
+				"filter": filters,
+				"must_not": map[string]interface{}{
+					"ids": map[string]interface{}{"values": []string{postID}},
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source struct {
+					PostID string `json:"post_id"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.searchRaw(ctx, query, &result); err != nil {
+		return nil, fmt.Errorf("ES related posts failed: %w", err)
+	}
+
+	ids := make([]string, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		ids = append(ids, hit.Source.PostID)
+	}
+	zap.L().Debug("ES related posts", zap.String("post_id", postID), zap.Int("hits", len(ids)))
+	return ids, nil
+}
@@ -6,45 +6,45 @@ import (
 	"encoding/json" // 提供 JSON 序列化和反序列化
 	"fmt"
 	"io"            // 提供 I/O 操作接口
+	"time"
+
+	"bluebell/internal/domain"
 
 	"github.com/elastic/go-elasticsearch/v8/esapi" // ES 官方 Go 客户端 API 类型定义
 	"go.uber.org/zap"                              // 高性能日志库
 )
 
-// SearchRequest 搜索请求结构体
-// 用于接收前端传来的搜索参数，包含关键词和分页信息
-type SearchRequest struct {
-	Keyword  string `json:"keyword" binding:"required"` // 搜索关键词（必填字段）
-	Page     int    `json:"page"`                       // 当前页码（从 1 开始，默认 1）
-	PageSize int    `json:"page_size"`                  // 每页条数（默认 20，最大 50）
+// searchIndices 返回搜索范围对应的索引别名
+func searchIndices(typ string) []string {
+	switch typ {
+	case domain.SearchTypeRemark:
+		return []string{IndexRemark}
+	case domain.SearchTypeAll:
+		return []string{IndexPost, IndexRemark}
+	default:
+		return []string{IndexPost}
+	}
 }
 
-// SearchResponse 搜索响应结构体
-// 返回给前端的标准化搜索结果
-type SearchResponse struct {
-	Total    int64           `json:"total"`     // 符合条件的总文档数
-	Page     int             `json:"page"`      // 当前页码
-	PageSize int             `json:"page_size"` // 每页条数
-	Posts    []SearchPostDoc `json:"posts"`     // 搜索结果文档列表
-}
+// communityFacetSize 社区分面最多返回的社区数
+const communityFacetSize = 20
 
-// SearchPostDoc 搜索结果文档结构体
-// 表示 ES 中返回的单条帖子文档，包含原始字段和高亮字段
-type SearchPostDoc struct {
-	PostID           string   `json:"post_id"`                     // 帖子唯一 ID
-	AuthorID         int64    `json:"author_id"`                   // 作者用户 ID
-	CommunityID      int64    `json:"community_id"`                // 所属社区 ID
-	PostTitle        string   `json:"post_title"`                  // 帖子标题（原始文本）
-	Content          string   `json:"content"`                     // 帖子内容（原始文本）
-	Status           int8     `json:"status"`                      // 帖子状态（1=已发布，0=已删除）
-	CreatedAt        string   `json:"created_at"`                  // 创建时间（RFC3339 格式）
-	HighlightTitle   []string `json:"highlight_title,omitempty"`   // 标题高亮片段（omitempty 表示为空时不返回）
-	HighlightContent []string `json:"highlight_content,omitempty"` // 内容高亮片段（omitempty 表示为空时不返回）
+// hitSource 索引文档的 _source，帖子和评论共用
+type hitSource struct {
+	RemarkID    string `json:"remark_id"`
+	PostID      string `json:"post_id"`
+	AuthorID    int64  `json:"author_id"`
+	CommunityID int64  `json:"community_id"`
+	PostTitle   string `json:"post_title"`
+	Content     string `json:"content"`
+	Status      int8   `json:"status"`
+	CreatedAt   string `json:"created_at"` // RFC3339 格式
+	VoteCount   int64  `json:"vote_count"`
 }
 
 // Search 执行全文搜索，支持高亮和分页
 // 这是 ES 搜索功能的核心入口方法
-func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+func (c *Client) Search(ctx context.Context, req *domain.SearchQuery) (*domain.SearchResult, error) {
 	// ====== 第一步：参数校验和默认值处理 ======
 	if req.Page < 1 {
 		req.Page = 1
@@ -65,6 +65,8 @@ func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchRespons
 	// ====== 第四步：记录搜索日志 ======
 	zap.L().Info("ES search",
 		zap.String("keyword", req.Keyword),
+		zap.String("sort", req.Sort),
+		zap.String("type", req.Type),
 		zap.Int("page", req.Page),
 		zap.Int("page_size", req.PageSize),
 	)
@@ -72,7 +74,7 @@ func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchRespons
 	// ====== 第五步：执行 ES 搜索请求 ======
 	res, err := c.es.Search(
 		c.es.Search.WithContext(ctx),
-		c.es.Search.WithIndex(IndexPost),
+		c.es.Search.WithIndex(searchIndices(req.Type)...),
 		c.es.Search.WithBody(bytes.NewReader(body)),
 		c.es.Search.WithTrackTotalHits(true),
 	)
@@ -101,24 +103,53 @@ func (c *Client) Search(ctx context.Context, req *SearchRequest) (*SearchRespons
 	resp.Page = req.Page
 	resp.PageSize = req.PageSize
 
+	// ====== 第九步：命中过少时给出纠错建议 ======
+	c.attachDidYouMean(ctx, req, resp)
+
 	return resp, nil
 }
 
 // buildSearchQuery 构建 ES 搜索 DSL
-func buildSearchQuery(req *SearchRequest) map[string]interface{} {
+//   - 关键词匹配放在 must 中参与打分，作者、时间等过滤条件放在 filter 中不影响相关度
+//   - 社区过滤放在 post_filter 中，只作用于命中结果而不影响社区分面的统计
+func buildSearchQuery(req *domain.SearchQuery) map[string]interface{} {
 	from := (req.Page - 1) * req.PageSize
 
+	filters := []map[string]interface{}{}
+	if req.AuthorID > 0 {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"author_id": req.AuthorID},
+		})
+	}
+	if !req.CreatedFrom.IsZero() || !req.CreatedTo.IsZero() {
+		createdAt := map[string]interface{}{}
+		if !req.CreatedFrom.IsZero() {
+			createdAt["gte"] = req.CreatedFrom.Format(time.RFC3339)
+		}
+		if !req.CreatedTo.IsZero() {
+			createdAt["lt"] = req.CreatedTo.Format(time.RFC3339)
+		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"created_at": createdAt},
+		})
+	}
+
 	query := map[string]interface{}{
 		"from": from,
 		"size": req.PageSize,
 		"query": map[string]interface{}{
-			"multi_match": map[string]interface{}{
-				"query": req.Keyword,
-				"fields": []string{
-					"post_title^2",
-					"content",
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query": req.Keyword,
+						"fields": []string{
+							"post_title^2",
+							"content",
+						},
+						"type": "best_fields",
+					},
 				},
-				"type": "best_fields",
+				"filter": filters,
 			},
 		},
 		"highlight": map[string]interface{}{
@@ -137,25 +168,59 @@ func buildSearchQuery(req *SearchRequest) map[string]interface{} {
 		},
 		"_source": map[string]interface{}{
 			"includes": []string{
+				"remark_id",
 				"post_id",
 				"author_id",
 				"community_id",
 				"post_title",
 				"content",
 				"status",
+				"vote_count",
 				"created_at",
 			},
 		},
-		"sort": []map[string]interface{}{
-			{"created_at": map[string]interface{}{"order": "desc"}},
+		"aggs": map[string]interface{}{
+			"communities": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "community_id",
+					"size":  communityFacetSize,
+				},
+			},
 		},
+		"sort": buildSearchSort(req.Sort),
+	}
+
+	if len(req.CommunityIDs) > 0 {
+		query["post_filter"] = map[string]interface{}{
+			"terms": map[string]interface{}{"community_id": req.CommunityIDs},
+		}
 	}
 
 	return query
 }
 
+// buildSearchSort 根据排序方式构建 sort 子句
+func buildSearchSort(sort string) []map[string]interface{} {
+	newest := map[string]interface{}{"created_at": map[string]interface{}{"order": "desc"}}
+	switch sort {
+	case domain.SearchSortNew:
+		return []map[string]interface{}{newest}
+	case domain.SearchSortTop:
+		// 旧索引中可能缺少 vote_count，按 0 票处理
+		return []map[string]interface{}{
+			{"vote_count": map[string]interface{}{"order": "desc", "missing": 0, "unmapped_type": "long"}},
+			newest,
+		}
+	default:
+		return []map[string]interface{}{
+			{"_score": map[string]interface{}{"order": "desc"}},
+			newest,
+		}
+	}
+}
+
 // parseSearchResponse 解析 ES 搜索响应
-func parseSearchResponse(res *esapi.Response) (*SearchResponse, error) {
+func parseSearchResponse(res *esapi.Response) (*domain.SearchResult, error) {
 	var result struct {
 		Hits struct {
 			Total struct {
@@ -166,6 +231,14 @@ func parseSearchResponse(res *esapi.Response) (*SearchResponse, error) {
 				Highlight map[string][]string `json:"highlight"`
 			} `json:"hits"`
 		} `json:"hits"`
+		Aggregations struct {
+			Communities struct {
+				Buckets []struct {
+					Key      int64 `json:"key"`
+					DocCount int64 `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"communities"`
+		} `json:"aggregations"`
 	}
 
 	body, err := io.ReadAll(res.Body)
@@ -177,24 +250,48 @@ func parseSearchResponse(res *esapi.Response) (*SearchResponse, error) {
 		return nil, fmt.Errorf("unmarshal search response failed: %w", err)
 	}
 
-	resp := &SearchResponse{
-		Total: result.Hits.Total.Value,
+	resp := &domain.SearchResult{
+		Total:       result.Hits.Total.Value,
+		Hits:        make([]domain.SearchHit, 0, len(result.Hits.Hits)),
+		Communities: make([]domain.CommunityFacet, 0, len(result.Aggregations.Communities.Buckets)),
+	}
+	for _, b := range result.Aggregations.Communities.Buckets {
+		resp.Communities = append(resp.Communities, domain.CommunityFacet{
+			CommunityID: b.Key,
+			Count:       b.DocCount,
+		})
 	}
 
 	for _, hit := range result.Hits.Hits {
-		var doc SearchPostDoc
-		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+		var src hitSource
+		if err := json.Unmarshal(hit.Source, &src); err != nil {
 			zap.L().Warn("Failed to parse search hit", zap.Error(err))
 			continue
 		}
 
+		doc := domain.SearchHit{
+			Type:        domain.SearchTypePost,
+			RemarkID:    src.RemarkID,
+			PostID:      src.PostID,
+			AuthorID:    src.AuthorID,
+			CommunityID: src.CommunityID,
+			Title:       src.PostTitle,
+			Content:     src.Content,
+			Status:      src.Status,
+			VoteCount:   src.VoteCount,
+		}
+		doc.CreatedAt, _ = time.Parse(time.RFC3339, src.CreatedAt)
+		if doc.RemarkID != "" {
+			doc.Type = domain.SearchTypeRemark
+		}
+
 		if hl, ok := hit.Highlight["post_title"]; ok && len(hl) > 0 {
 			doc.HighlightTitle = hl
 		}
 		if hl, ok := hit.Highlight["content"]; ok && len(hl) > 0 {
 			doc.HighlightContent = hl
 		}
-		resp.Posts = append(resp.Posts, doc)
+		resp.Hits = append(resp.Hits, doc)
 	}
 
 	return resp, nil
@@ -0,0 +1,143 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"bluebell/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// suggestTimeout 输入联想的超时时间，超时直接放弃而不是拖慢输入框
+	suggestTimeout = 300 * time.Millisecond
+	// didYouMeanThreshold 命中数低于该值时尝试给出纠错建议
+	didYouMeanThreshold = 3
+)
+
+// Suggest 按前缀返回帖子标题联想，基于 post_title.suggest 补全字段
+// 补全字段由 PostMapping 定义，旧索引需通过 cmd/reindex 重建后才可用
+func (c *Client) Suggest(ctx context.Context, prefix string, size int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, suggestTimeout)
+	defer cancel()
+
+	query := map[string]interface{}{
+		"_source": false,
+		"suggest": map[string]interface{}{
+			"title": map[string]interface{}{
+				"prefix": prefix,
+				"completion": map[string]interface{}{
+					"field":           "post_title.suggest",
+					"size":            size,
+					"skip_duplicates": true,
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Suggest struct {
+			Title []struct {
+				Options []struct {
+					Text string `json:"text"`
+				} `json:"options"`
+			} `json:"title"`
+		} `json:"suggest"`
+	}
+	if err := c.searchRaw(ctx, query, &result); err != nil {
+		return nil, fmt.Errorf("ES suggest failed: %w", err)
+	}
+
+	titles := make([]string, 0, size)
+	for _, s := range result.Suggest.Title {
+		for _, o := range s.Options {
+			titles = append(titles, o.Text)
+		}
+	}
+	return titles, nil
+}
+
+// didYouMean 使用 phrase suggester 对关键词纠错，没有更好的候选时返回空字符串
+func (c *Client) didYouMean(ctx context.Context, keyword string) (string, error) {
+	query := map[string]interface{}{
+		"size": 0,
+		"suggest": map[string]interface{}{
+			"text": keyword,
+			"did_you_mean": map[string]interface{}{
+				"phrase": map[string]interface{}{
+					"field": "post_title",
+					"size":  1,
+					"direct_generator": []map[string]interface{}{
+						{"field": "post_title", "suggest_mode": "always"},
+					},
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Suggest struct {
+			DidYouMean []struct {
+				Options []struct {
+					Text string `json:"text"`
+				} `json:"options"`
+			} `json:"did_you_mean"`
+		} `json:"suggest"`
+	}
+	if err := c.searchRaw(ctx, query, &result); err != nil {
+		return "", err
+	}
+	for _, s := range result.Suggest.DidYouMean {
+		for _, o := range s.Options {
+			if o.Text != keyword {
+				return o.Text, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// searchRaw 对帖子别名执行一次 _search 请求并把响应解码到 out
+func (c *Client) searchRaw(ctx context.Context, query map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return fmt.Errorf("marshal query failed: %w", err)
+	}
+
+	res, err := c.es.Search(
+		c.es.Search.WithContext(ctx),
+		c.es.Search.WithIndex(IndexPost),
+		c.es.Search.WithBody(bytes.NewReader(body)),
+	)
+	if err != nil {
+		return fmt.Errorf("ES search request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		respBody, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("ES search error: %s", string(respBody))
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode search response failed: %w", err)
+	}
+	return nil
+}
+
+// attachDidYouMean 命中过少时为搜索结果附加纠错建议，失败只记录日志
+func (c *Client) attachDidYouMean(ctx context.Context, req *domain.SearchQuery, resp *domain.SearchResult) {
+	if resp.Total >= didYouMeanThreshold {
+		return
+	}
+	suggestion, err := c.didYouMean(ctx, req.Keyword)
+	if err != nil {
+		zap.L().Warn("ES did-you-mean suggest failed", zap.String("keyword", req.Keyword), zap.Error(err))
+		return
+	}
+	resp.Suggestion = suggestion
+}
@@ -0,0 +1,58 @@
+// Package eventbus 进程内领域事件总线
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/event"
+)
+
+// Bus 进程内事件总线，实现 domain.EventBus
+// 事件按订阅顺序同步分发，单个处理器失败不影响其余处理器
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]domain.EventHandler
+}
+
+// New 创建事件总线
+func New() *Bus {
+	return &Bus{handlers: make(map[string][]domain.EventHandler)}
+}
+
+// Subscribe 订阅指定名称的事件
+func (b *Bus) Subscribe(name string, handler domain.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish 依次分发事件，返回所有处理器错误的合并结果
+func (b *Bus) Publish(ctx context.Context, events ...event.Event) error {
+	var errs []error
+	for _, e := range events {
+		b.mu.RLock()
+		handlers := b.handlers[e.EventName()]
+		b.mu.RUnlock()
+
+		for _, h := range handlers {
+			if err := dispatch(ctx, h, e); err != nil {
+				errs = append(errs, fmt.Errorf("handle event %s: %w", e.EventName(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatch 调用单个处理器，处理器 panic 时转换为错误，避免影响发布方
+func dispatch(ctx context.Context, h domain.EventHandler, e event.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return h(ctx, e)
+}
@@ -0,0 +1,176 @@
+// Package health 存活与就绪检查
+//
+// /healthz 只表示进程仍在运行，供存活探针使用，不检查任何依赖；
+// /readyz 并发探测各依赖，每个依赖有独立的超时。关键依赖（MySQL、Redis）失败时返回 503，
+// 可选依赖（ES、MQ）失败只把整体状态标记为 degraded，仍返回 200，避免把实例摘出流量
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"bluebell/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// 检查状态
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// defaultCheckTimeout 未配置时单个依赖的检查超时
+const defaultCheckTimeout = time.Second
+
+// Check 一项依赖检查
+type Check struct {
+	Name     string
+	Critical bool                            // 关键依赖失败时实例不就绪
+	Probe    func(ctx context.Context) error // 返回 nil 表示依赖可用，须遵守 ctx 的超时
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report 就绪检查结果
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Checker 依赖检查集合，Add 须在开始提供服务前完成
+type Checker struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// NewChecker 创建检查集合，timeout 为单个依赖的检查超时
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// NewCheckerFromConfig 按配置创建检查集合
+func NewCheckerFromConfig(cfg *config.Config) *Checker {
+	return NewChecker(cfg.Health.CheckTimeout)
+}
+
+// Add 添加一项检查
+func (c *Checker) Add(check Check) {
+	c.checks = append(c.checks, check)
+}
+
+// Check 并发执行所有检查
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusUp,
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+		Timestamp: time.Now(),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			switch {
+			case result.Status == StatusUp:
+			case check.Critical:
+				report.Status = StatusDown
+			case report.Status == StatusUp:
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// run 在独立的超时内执行一项检查
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler 存活检查：进程能响应即视为存活
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+	})
+}
+
+// ReadinessHandler 就绪检查：关键依赖不可用时返回 503
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		code := http.StatusOK
+		if report.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+			zap.L().Warn("readiness check failed", zap.Any("checks", report.Checks))
+		}
+		writeJSON(w, code, report)
+	})
+}
+
+// Serve 为没有业务 HTTP 服务的进程（如 MQ 消费者）单独监听健康检查端点
+// 返回的 Server 在退出时调用 Shutdown 关闭
+func Serve(port int, checker *Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		zap.L().Info("health server is running...", zap.Int("port", port))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			zap.L().Error("health server listen failed", zap.Error(err))
+		}
+	}()
+	return srv
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
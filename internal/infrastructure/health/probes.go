@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// MySQL 检查数据库连通性
+func MySQL(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Redis 检查 Redis 连通性
+func Redis(rdb *redis.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	}
+}
+
+// connectionState 能报告当前连接状态的 MQ 连接，如 mq.ConnectionManager
+type connectionState interface {
+	IsConnected() bool
+}
+
+// RabbitMQ 检查 MQ 连接状态；断线重连期间视为不可用
+func RabbitMQ(conn connectionState) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !conn.IsConnected() {
+			return errors.New("rabbitmq disconnected, reconnecting")
+		}
+		return nil
+	}
+}
+
+// clusterHealth 能查询集群健康状态的 ES 客户端，如 es.Client
+type clusterHealth interface {
+	ClusterHealth(ctx context.Context) (string, error)
+}
+
+// Elasticsearch 检查 ES 集群健康；yellow（副本未分配，单节点部署的常态）视为可用，red 视为不可用
+func Elasticsearch(client clusterHealth) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		status, err := client.ClusterHealth(ctx)
+		if err != nil {
+			return err
+		}
+		if status == "red" {
+			return fmt.Errorf("elasticsearch cluster status is %s", status)
+		}
+		return nil
+	}
+}
+
+// Unavailable 启动时初始化失败的依赖，始终报告为不可用
+func Unavailable(err error) func(ctx context.Context) error {
+	return func(context.Context) error {
+		return fmt.Errorf("not initialized: %w", err)
+	}
+}
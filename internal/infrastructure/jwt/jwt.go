@@ -25,15 +25,6 @@ type CustomClaims struct {
 	jwt.RegisteredClaims
 }
 
-// mustParseDuration 解析时间字符串，失败时 panic（配置错误属于启动期致命错误）
-func mustParseDuration(s string) time.Duration {
-	d, err := time.ParseDuration(s)
-	if err != nil {
-		panic("jwt: invalid duration string: " + s)
-	}
-	return d
-}
-
 // ParseToken 解析并验证 Token，返回 userID 并校验 token 类型
 func ParseToken(cfg *config.Config, tokenString string, expectedType TokenType) (userID int64, err error) {
 	claims := new(CustomClaims)
@@ -68,7 +59,7 @@ func GenToken(cfg *config.Config, userID int64) (aToken, rToken string, err erro
 		TokenType: AccessTokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   fmt.Sprintf("%d", userID),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mustParseDuration(cfg.JWT.AccessExpiry))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.AccessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -81,7 +72,7 @@ func GenToken(cfg *config.Config, userID int64) (aToken, rToken string, err erro
 		TokenType: RefreshTokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   fmt.Sprintf("%d", userID),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mustParseDuration(cfg.JWT.RefreshExpiry))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.RefreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
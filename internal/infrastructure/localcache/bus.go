@@ -0,0 +1,126 @@
+package localcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Invalidatable 可被失效消息清理的缓存
+type Invalidatable interface {
+	Name() string
+	Delete(keys ...string)
+	Purge()
+}
+
+// invalidation 失效消息，Keys 为空表示清空整个缓存
+type invalidation struct {
+	Node  string   `json:"node"`
+	Cache string   `json:"cache"`
+	Keys  []string `json:"keys,omitempty"`
+}
+
+// Bus 基于 Redis Pub/Sub 的失效广播
+// 本地缓存在写入方立即失效，再广播给其他副本；各副本收到消息后清理同名缓存。
+// Pub/Sub 不保证送达（订阅断线重连期间的消息会丢失），缓存的 TTL 是一致性的最终兜底
+type Bus struct {
+	rdb     *redis.Client
+	channel string
+	node    string // 本进程标识，收到自己发出的消息时跳过
+
+	mu     sync.RWMutex
+	caches map[string]Invalidatable
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+// NewBus 创建失效广播
+func NewBus(rdb *redis.Client, channel string) *Bus {
+	return &Bus{
+		rdb:     rdb,
+		channel: channel,
+		node:    uuid.NewString(),
+		caches:  make(map[string]Invalidatable),
+		done:    make(chan struct{}),
+	}
+}
+
+// Register 注册缓存，之后收到该名称的失效消息时清理它
+func (b *Bus) Register(caches ...Invalidatable) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range caches {
+		b.caches[c.Name()] = c
+	}
+}
+
+// Invalidate 失效本地缓存并广播给其他副本，keys 为空时清空整个缓存
+// 广播失败只影响其他副本（由 TTL 兜底），返回的错误供调用方记录
+func (b *Bus) Invalidate(ctx context.Context, cache string, keys ...string) error {
+	b.apply(cache, keys)
+
+	payload, err := json.Marshal(invalidation{Node: b.node, Cache: cache, Keys: keys})
+	if err != nil {
+		return fmt.Errorf("marshal invalidation: %w", err)
+	}
+	if err := b.rdb.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("publish invalidation (cache: %s): %w", cache, err)
+	}
+	return nil
+}
+
+// Start 订阅失效频道，在独立协程中处理消息，直到 Close
+func (b *Bus) Start(ctx context.Context) error {
+	b.pubsub = b.rdb.Subscribe(ctx, b.channel)
+	// 等待订阅确认，确保返回后不会漏掉之后发布的消息
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		_ = b.pubsub.Close()
+		return fmt.Errorf("subscribe %s: %w", b.channel, err)
+	}
+
+	go func() {
+		defer close(b.done)
+		for msg := range b.pubsub.Channel() {
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				zap.L().Warn("malformed local cache invalidation", zap.String("payload", msg.Payload), zap.Error(err))
+				continue
+			}
+			if inv.Node == b.node {
+				continue
+			}
+			b.apply(inv.Cache, inv.Keys)
+		}
+	}()
+	return nil
+}
+
+// Close 取消订阅并等待处理协程退出
+func (b *Bus) Close() error {
+	if b.pubsub == nil {
+		return nil
+	}
+	err := b.pubsub.Close()
+	<-b.done
+	return err
+}
+
+func (b *Bus) apply(name string, keys []string) {
+	b.mu.RLock()
+	c, ok := b.caches[name]
+	b.mu.RUnlock()
+	if !ok {
+		return
+	}
+	if len(keys) == 0 {
+		c.Purge()
+		return
+	}
+	c.Delete(keys...)
+}
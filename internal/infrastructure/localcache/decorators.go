@@ -0,0 +1,103 @@
+package localcache
+
+import (
+	"context"
+	"fmt"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+// 缓存名称，对应配置 local_cache.caches 下的键
+const (
+	NameCommunityList = "community_list"
+	NameHotFeed       = "hot_feed"
+)
+
+// HotFeedMaxPage 热门帖子列表只缓存前几页，更靠后的页访问分散，缓存收益低
+const HotFeedMaxPage = 5
+
+// ========== 社区列表 ==========
+
+// communityRepo 社区列表走本地缓存的社区仓储
+type communityRepo struct {
+	domain.CommunityRepository
+	cache *Cache[[]*entity.Community]
+	bus   *Bus
+}
+
+// NewCommunityRepository 包装社区仓储，GetCommunityList 先查本地缓存，创建社区后广播失效
+func NewCommunityRepository(next domain.CommunityRepository, cache *Cache[[]*entity.Community], bus *Bus) domain.CommunityRepository {
+	bus.Register(cache)
+	return &communityRepo{CommunityRepository: next, cache: cache, bus: bus}
+}
+
+const communityListKey = "all"
+
+func (r *communityRepo) GetCommunityList(ctx context.Context) ([]*entity.Community, error) {
+	if list, ok := r.cache.Get(communityListKey); ok {
+		return list, nil
+	}
+	list, err := r.CommunityRepository.GetCommunityList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(communityListKey, list)
+	return list, nil
+}
+
+func (r *communityRepo) CreateCommunity(ctx context.Context, community *entity.Community) error {
+	if err := r.CommunityRepository.CreateCommunity(ctx, community); err != nil {
+		return err
+	}
+	if err := r.bus.Invalidate(ctx, r.cache.Name()); err != nil {
+		logger.WithContext(ctx).Warn("broadcast community list invalidation failed", zap.Error(err))
+	}
+	return nil
+}
+
+// ========== 热门帖子列表 ==========
+
+// feedCache 热门帖子列表前几页走本地缓存的帖子缓存仓储
+type feedCache struct {
+	domain.PostCacheRepository
+	cache *Cache[[]string]
+	bus   *Bus
+}
+
+// NewPostCacheRepository 包装帖子缓存仓储，按热度排序的前 HotFeedMaxPage 页帖子 ID 先查本地缓存
+// 投票和新帖带来的排序变化依赖较短的 TTL 收敛；删除帖子后立即广播失效，避免已删除的帖子继续出现在列表中
+func NewPostCacheRepository(next domain.PostCacheRepository, cache *Cache[[]string], bus *Bus) domain.PostCacheRepository {
+	bus.Register(cache)
+	return &feedCache{PostCacheRepository: next, cache: cache, bus: bus}
+}
+
+func (r *feedCache) GetPostIDsInOrder(ctx context.Context, orderKey string, page, size int64) ([]string, error) {
+	if orderKey != "score" || page > HotFeedMaxPage {
+		return r.PostCacheRepository.GetPostIDsInOrder(ctx, orderKey, page, size)
+	}
+
+	key := fmt.Sprintf("%d:%d", page, size)
+	if ids, ok := r.cache.Get(key); ok {
+		return ids, nil
+	}
+	ids, err := r.PostCacheRepository.GetPostIDsInOrder(ctx, orderKey, page, size)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(key, ids)
+	return ids, nil
+}
+
+func (r *feedCache) DeletePost(ctx context.Context, postID, communityID int64) error {
+	if err := r.PostCacheRepository.DeletePost(ctx, postID, communityID); err != nil {
+		return err
+	}
+	if err := r.bus.Invalidate(ctx, r.cache.Name()); err != nil {
+		logger.WithContext(ctx).Warn("broadcast hot feed invalidation failed", zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Package localcache 进程内 LRU 缓存
+//
+// 作为 Redis 之前的一级缓存，用于社区列表、热门帖子首页等读多写少、允许秒级延迟的数据。
+// 多副本之间通过 Redis Pub/Sub 广播失效消息保持一致（见 Bus），TTL 兜底订阅断开期间漏掉的消息
+package localcache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"bluebell/internal/infrastructure/metrics"
+)
+
+// Stats 缓存统计
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64 // 因容量淘汰的条目数，不含过期和主动失效
+	Size      int
+}
+
+type entry[V any] struct {
+	key      string
+	value    V
+	expireAt time.Time
+}
+
+// Cache 固定容量、带 TTL 的 LRU 缓存，并发安全
+// 返回的值与缓存共享，调用方不应修改
+type Cache[V any] struct {
+	name     string
+	capacity int
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	ll    *list.List // 头部为最近使用
+	items map[string]*list.Element
+	stats Stats
+
+	now func() time.Time
+}
+
+// New 创建缓存，name 用于指标标签和失效消息路由，同一进程内应唯一
+func New[V any](name string, capacity int, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		name:     name,
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+		now:      time.Now,
+	}
+}
+
+// Name 缓存名称
+func (c *Cache[V]) Name() string { return c.name }
+
+// Get 读取未过期的缓存项
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		if c.now().Before(e.expireAt) {
+			c.ll.MoveToFront(el)
+			c.stats.Hits++
+			metrics.LocalCacheLookups.WithLabelValues(c.name, "hit").Inc()
+			return e.value, true
+		}
+		c.removeElement(el)
+	}
+
+	c.stats.Misses++
+	metrics.LocalCacheLookups.WithLabelValues(c.name, "miss").Inc()
+	var zero V
+	return zero, false
+}
+
+// Set 写入缓存项，超出容量时淘汰最久未使用的项
+func (c *Cache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expireAt := c.now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		e.value, e.expireAt = value, expireAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, expireAt: expireAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+		c.stats.Evictions++
+		metrics.LocalCacheEvictions.WithLabelValues(c.name).Inc()
+	}
+	metrics.LocalCacheEntries.WithLabelValues(c.name).Set(float64(c.ll.Len()))
+}
+
+// Delete 删除指定缓存项
+func (c *Cache[V]) Delete(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.removeElement(el)
+		}
+	}
+}
+
+// Purge 清空缓存
+func (c *Cache[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	clear(c.items)
+	metrics.LocalCacheEntries.WithLabelValues(c.name).Set(0)
+}
+
+// Stats 返回统计快照
+func (c *Cache[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.ll.Len()
+	return s
+}
+
+// removeElement 调用方需持有锁
+func (c *Cache[V]) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+	metrics.LocalCacheEntries.WithLabelValues(c.name).Set(float64(c.ll.Len()))
+}
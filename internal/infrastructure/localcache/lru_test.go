@@ -0,0 +1,71 @@
+package localcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[int]("test_lru", 2, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	c.Set("c", 3) // b 最久未使用，被淘汰
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) should miss after eviction")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Get(%s) missed", key)
+		}
+	}
+
+	s := c.Stats()
+	if s.Size != 2 || s.Evictions != 1 || s.Hits != 3 || s.Misses != 1 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestCache_Expires(t *testing.T) {
+	now := time.Now()
+	c := New[string]("test_ttl", 10, time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Set("k", "v")
+	if v, ok := c.Get("k"); !ok || v != "v" {
+		t.Fatalf("Get(k) = %q, %v", v, ok)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := c.Get("k"); ok {
+		t.Error("Get(k) should miss after TTL")
+	}
+	if s := c.Stats(); s.Size != 0 {
+		t.Errorf("expired entry not removed, size = %d", s.Size)
+	}
+}
+
+func TestBus_ApplyInvalidation(t *testing.T) {
+	c := New[int]("test_bus", 10, time.Minute)
+	b := NewBus(nil, "unused")
+	b.Register(c)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	b.apply("test_bus", []string{"a"})
+	if _, ok := c.Get("a"); ok {
+		t.Error("a should be invalidated")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("b should survive key invalidation")
+	}
+
+	b.apply("test_bus", nil)
+	if s := c.Stats(); s.Size != 0 {
+		t.Errorf("purge left %d entries", s.Size)
+	}
+	b.apply("unknown", nil) // 未注册的缓存名称直接忽略
+}
@@ -0,0 +1,293 @@
+// Package localsearch 基于本地文件的嵌入式全文搜索，实现 domain.SearchBackend 和 domain.SearchIndexer
+//
+// 用于没有 Elasticsearch 的开发机和 CI：文档保存在内存中的倒排索引里，
+// 并定期以 JSON 快照落盘，进程重启后从快照恢复。只适合单进程、小数据量场景
+package localsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// flushInterval 快照落盘间隔
+const flushInterval = time.Second
+
+// document 索引中的一条文档（帖子或评论）
+type document struct {
+	Type        string    `json:"type"` // domain.SearchTypePost | domain.SearchTypeRemark
+	RemarkID    string    `json:"remark_id,omitempty"`
+	PostID      string    `json:"post_id"`
+	AuthorID    int64     `json:"author_id"`
+	CommunityID int64     `json:"community_id"`
+	Title       string    `json:"title,omitempty"`
+	Content     string    `json:"content"`
+	Status      int8      `json:"status"`
+	VoteCount   int64     `json:"vote_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// key 文档在索引中的唯一键
+func (d *document) key() string {
+	if d.Type == domain.SearchTypeRemark {
+		return "r:" + d.RemarkID
+	}
+	return "p:" + d.PostID
+}
+
+// posting 某个检索词在一篇文档中的词频
+type posting struct {
+	title   int
+	content int
+}
+
+// Index 嵌入式全文索引
+type Index struct {
+	path string
+
+	mu       sync.RWMutex
+	docs     map[string]*document          // 文档写入后只读，修改时整体替换，flush 可在锁外序列化
+	postings map[string]map[string]posting // 检索词 → 文档键 → 词频
+	dirty    bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open 打开（或新建）path 处的索引快照，并启动后台落盘
+func Open(path string) (*Index, error) {
+	idx := &Index{
+		path:     path,
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]posting),
+		done:     make(chan struct{}),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create search index dir failed: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("read search index failed: %w", err)
+	default:
+		var docs []*document
+		if err := json.Unmarshal(data, &docs); err != nil {
+			return nil, fmt.Errorf("decode search index failed: %w", err)
+		}
+		for _, d := range docs {
+			idx.put(d)
+		}
+	}
+	zap.L().Info("embedded search index opened", zap.String("path", path), zap.Int("docs", len(idx.docs)))
+
+	idx.wg.Add(1)
+	go idx.flushLoop()
+	return idx, nil
+}
+
+// Close 停止后台落盘并写出最后一次快照
+func (idx *Index) Close() error {
+	close(idx.done)
+	idx.wg.Wait()
+	return idx.flush()
+}
+
+func (idx *Index) flushLoop() {
+	defer idx.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-idx.done:
+			return
+		case <-ticker.C:
+			if err := idx.flush(); err != nil {
+				zap.L().Error("flush embedded search index failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// flush 有改动时把全部文档写入临时文件再原子替换快照
+func (idx *Index) flush() error {
+	idx.mu.Lock()
+	if !idx.dirty {
+		idx.mu.Unlock()
+		return nil
+	}
+	docs := make([]*document, 0, len(idx.docs))
+	for _, d := range idx.docs {
+		docs = append(docs, d)
+	}
+	idx.dirty = false
+	idx.mu.Unlock()
+
+	if err := idx.writeSnapshot(docs); err != nil {
+		// 写入失败时恢复脏标记，下次落盘重试
+		idx.mu.Lock()
+		idx.dirty = true
+		idx.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (idx *Index) writeSnapshot(docs []*document) error {
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("encode search index failed: %w", err)
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write search index failed: %w", err)
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return fmt.Errorf("replace search index failed: %w", err)
+	}
+	return nil
+}
+
+// put 写入文档并更新倒排索引，调用方持有写锁
+func (idx *Index) put(d *document) {
+	key := d.key()
+	idx.remove(key)
+	idx.docs[key] = d
+
+	for t, n := range termFrequencies(d.Title) {
+		idx.addPosting(t, key, func(p *posting) { p.title = n })
+	}
+	for t, n := range termFrequencies(d.Content) {
+		idx.addPosting(t, key, func(p *posting) { p.content = n })
+	}
+	idx.dirty = true
+}
+
+func (idx *Index) addPosting(term, key string, set func(p *posting)) {
+	docs, ok := idx.postings[term]
+	if !ok {
+		docs = make(map[string]posting)
+		idx.postings[term] = docs
+	}
+	p := docs[key]
+	set(&p)
+	docs[key] = p
+}
+
+// remove 删除文档及其倒排记录，调用方持有写锁
+func (idx *Index) remove(key string) {
+	d, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for _, text := range []string{d.Title, d.Content} {
+		for t := range termFrequencies(text) {
+			if docs, ok := idx.postings[t]; ok {
+				delete(docs, key)
+				if len(docs) == 0 {
+					delete(idx.postings, t)
+				}
+			}
+		}
+	}
+	delete(idx.docs, key)
+	idx.dirty = true
+}
+
+// ========== domain.SearchIndexer ==========
+
+// IndexPost 写入帖子文档，已索引的帖子保留原有票数
+func (idx *Index) IndexPost(_ context.Context, doc *domain.PostDocument) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	voteCount := doc.VoteCount
+	if d, ok := idx.docs["p:"+doc.PostID]; ok {
+		voteCount = d.VoteCount
+	}
+	idx.put(&document{
+		Type:        domain.SearchTypePost,
+		PostID:      doc.PostID,
+		AuthorID:    doc.AuthorID,
+		CommunityID: doc.CommunityID,
+		Title:       doc.Title,
+		Content:     doc.Content,
+		Status:      doc.Status,
+		VoteCount:   voteCount,
+		CreatedAt:   doc.CreatedAt,
+	})
+	return nil
+}
+
+// DeletePost 删除帖子文档
+func (idx *Index) DeletePost(_ context.Context, postID string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove("p:" + postID)
+	return nil
+}
+
+// UpdatePostVotes 更新帖子票数，帖子未索引时忽略
+func (idx *Index) UpdatePostVotes(_ context.Context, postID string, voteCount int64) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	key := "p:" + postID
+	if d, ok := idx.docs[key]; ok {
+		// 替换为副本而不是原地修改，flush 可能正在锁外序列化旧文档
+		updated := *d
+		updated.VoteCount = voteCount
+		idx.docs[key] = &updated
+		idx.dirty = true
+	}
+	return nil
+}
+
+// IndexRemark 写入评论文档
+func (idx *Index) IndexRemark(_ context.Context, doc *domain.RemarkDocument) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.put(&document{
+		Type:        domain.SearchTypeRemark,
+		RemarkID:    doc.RemarkID,
+		PostID:      doc.PostID,
+		AuthorID:    doc.AuthorID,
+		CommunityID: doc.CommunityID,
+		Content:     doc.Content,
+		CreatedAt:   doc.CreatedAt,
+	})
+	return nil
+}
+
+// DeleteRemark 删除评论文档
+func (idx *Index) DeleteRemark(_ context.Context, remarkID string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove("r:" + remarkID)
+	return nil
+}
+
+// DeletePostRemarks 删除帖子下的全部评论文档
+func (idx *Index) DeletePostRemarks(_ context.Context, postID string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for key, d := range idx.docs {
+		if d.Type == domain.SearchTypeRemark && d.PostID == postID {
+			idx.remove(key)
+		}
+	}
+	return nil
+}
+
+var (
+	_ domain.SearchBackend = (*Index)(nil)
+	_ domain.SearchIndexer = (*Index)(nil)
+)
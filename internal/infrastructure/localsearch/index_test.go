@@ -0,0 +1,149 @@
+package localsearch
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+)
+
+func openTestIndex(t *testing.T, path string) *Index {
+	t.Helper()
+	idx, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return idx
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Go语言实战, Gin框架!")
+	want := []string{"go", "语言", "言实", "实战", "gin", "框架"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %q, want %q", got, want)
+	}
+	if got := tokenize("我"); !reflect.DeepEqual(got, []string{"我"}) {
+		t.Errorf("tokenize(single) = %q, want [我]", got)
+	}
+}
+
+func TestIndex_SearchAndPersist(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index.json")
+	idx := openTestIndex(t, path)
+
+	now := time.Now()
+	posts := []*domain.PostDocument{
+		{PostID: "1", AuthorID: 10, CommunityID: 1, Title: "Go 语言实战", Content: "并发编程", Status: 1, CreatedAt: now.Add(-2 * time.Hour)},
+		{PostID: "2", AuthorID: 11, CommunityID: 2, Title: "Rust 入门", Content: "和 Go 语言的对比", Status: 1, VoteCount: 5, CreatedAt: now.Add(-time.Hour)},
+		{PostID: "3", AuthorID: 10, CommunityID: 1, Title: "今日天气", Content: "晴", Status: 1, CreatedAt: now},
+	}
+	for _, p := range posts {
+		if err := idx.IndexPost(ctx, p); err != nil {
+			t.Fatalf("IndexPost() error = %v", err)
+		}
+	}
+	_ = idx.IndexRemark(ctx, &domain.RemarkDocument{RemarkID: "100", PostID: "3", CommunityID: 1, Content: "Go 语言真好", CreatedAt: now})
+
+	res, _ := idx.Search(ctx, &domain.SearchQuery{Keyword: "go语言"})
+	if res.Total != 2 || res.Hits[0].PostID != "1" {
+		t.Fatalf("Search() total = %d, first = %+v; want 2 hits with title match first", res.Total, res.Hits)
+	}
+	if want := []string{"<em class='highlight'>Go</em> <em class='highlight'>语言</em>实战"}; !reflect.DeepEqual(res.Hits[0].HighlightTitle, want) {
+		t.Errorf("HighlightTitle = %q, want %q", res.Hits[0].HighlightTitle, want)
+	}
+
+	// 社区过滤只作用于命中结果，分面仍统计两个社区
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "go", CommunityIDs: []int64{2}, Sort: domain.SearchSortTop})
+	if res.Total != 1 || res.Hits[0].PostID != "2" || len(res.Communities) != 2 {
+		t.Errorf("Search(community) = %+v, want post 2 and two facets", res)
+	}
+
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "go", Type: domain.SearchTypeAll, AuthorID: 10})
+	if res.Total != 1 || res.Hits[0].PostID != "1" {
+		t.Errorf("Search(author) = %+v, want post 1 only", res.Hits)
+	}
+
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "语言", Type: domain.SearchTypeRemark})
+	if res.Total != 1 || res.Hits[0].RemarkID != "100" {
+		t.Errorf("Search(remark) = %+v, want remark 100", res.Hits)
+	}
+
+	if titles, _ := idx.Suggest(ctx, "go", 5); !reflect.DeepEqual(titles, []string{"Go 语言实战"}) {
+		t.Errorf("Suggest() = %q", titles)
+	}
+	if ids, _ := idx.RelatedPostIDs(ctx, "1", 0, 5); !reflect.DeepEqual(ids, []string{"2"}) {
+		t.Errorf("RelatedPostIDs() = %q, want [2]", ids)
+	}
+
+	// 删除后重新打开，快照中应只剩剩余文档
+	_ = idx.DeletePost(ctx, "2")
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	idx = openTestIndex(t, path)
+	defer idx.Close()
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "go", Type: domain.SearchTypeAll})
+	if res.Total != 2 {
+		t.Errorf("Search() after reopen total = %d, want 2", res.Total)
+	}
+}
+
+func TestIndex_ConcurrentVotesAndFlush(t *testing.T) {
+	ctx := context.Background()
+	idx := openTestIndex(t, filepath.Join(t.TempDir(), "index.json"))
+	defer idx.Close()
+	_ = idx.IndexPost(ctx, &domain.PostDocument{PostID: "1", Title: "Go", Content: "并发", CreatedAt: time.Now()})
+
+	// 票数更新与落盘交替进行，go test -race 下不应报告数据竞争
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := int64(0); i < 200; i++ {
+			_ = idx.UpdatePostVotes(ctx, "1", i)
+			runtime.Gosched()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if err := idx.flush(); err != nil {
+				t.Errorf("flush() error = %v", err)
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+	wg.Wait()
+
+	q := &domain.SearchQuery{Keyword: "go"}
+	res, _ := idx.Search(ctx, q)
+	if res.Total != 1 || res.Hits[0].VoteCount != 199 {
+		t.Errorf("Search() = %+v, want post 1 with 199 votes", res.Hits)
+	}
+	if q.Page != 0 || q.PageSize != 0 {
+		t.Errorf("Search() mutated query: page = %d, page_size = %d", q.Page, q.PageSize)
+	}
+}
+
+func TestIndex_ReindexKeepsVoteCount(t *testing.T) {
+	ctx := context.Background()
+	idx := openTestIndex(t, filepath.Join(t.TempDir(), "index.json"))
+	defer idx.Close()
+	_ = idx.IndexPost(ctx, &domain.PostDocument{PostID: "1", Title: "Go", Content: "并发", VoteCount: 2, CreatedAt: time.Now()})
+	_ = idx.UpdatePostVotes(ctx, "1", 7)
+
+	// 编辑后重新索引：消息不含票数，不应把票数清零
+	_ = idx.IndexPost(ctx, &domain.PostDocument{PostID: "1", Title: "Go 进阶", Content: "并发", CreatedAt: time.Now()})
+
+	res, _ := idx.Search(ctx, &domain.SearchQuery{Keyword: "进阶"})
+	if res.Total != 1 || res.Hits[0].VoteCount != 7 {
+		t.Errorf("Search() = %+v, want edited post 1 with 7 votes", res.Hits)
+	}
+}
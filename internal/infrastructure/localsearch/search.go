@@ -0,0 +1,406 @@
+package localsearch
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"bluebell/internal/domain"
+)
+
+const (
+	// titleBoost 标题命中的权重倍数，与 ES 查询中的 post_title^2 对应
+	titleBoost = 2
+	// communityFacetSize 社区分面最多返回的社区数
+	communityFacetSize = 20
+	// fragmentSize 内容高亮片段的长度（字符数）
+	fragmentSize = 150
+	// relatedTerms 查找相关帖子时从源帖子中选取的关键词数
+	relatedTerms = 25
+
+	highlightPre  = "<em class='highlight'>"
+	highlightPost = "</em>"
+)
+
+// scored 带相关度得分的文档
+type scored struct {
+	doc   *document
+	score float64
+}
+
+// Search 全文搜索，语义与 ES 实现保持一致：
+// 关键词任一检索词命中即召回，作者和时间过滤影响分面，社区过滤只作用于命中结果
+func (idx *Index) Search(_ context.Context, query *domain.SearchQuery) (*domain.SearchResult, error) {
+	q := *query // 分页归一化不修改调用方的查询
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 || q.PageSize > 50 {
+		q.PageSize = 20
+	}
+	terms := uniqueTerms(q.Keyword)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := idx.score(terms, func(d *document) bool {
+		return matchType(d, q.Type) && matchFilters(d, &q)
+	})
+
+	// 社区分面基于社区过滤之前的结果统计
+	facetCounts := make(map[int64]int64)
+	for _, s := range scores {
+		facetCounts[s.doc.CommunityID]++
+	}
+
+	hits := scores[:0]
+	for _, s := range scores {
+		if matchCommunity(s.doc, q.CommunityIDs) {
+			hits = append(hits, s)
+		}
+	}
+	sortHits(hits, q.Sort)
+
+	resp := &domain.SearchResult{
+		Total:       int64(len(hits)),
+		Page:        q.Page,
+		PageSize:    q.PageSize,
+		Communities: buildFacets(facetCounts),
+	}
+	from := (q.Page - 1) * q.PageSize
+	if from > len(hits) {
+		from = len(hits)
+	}
+	to := from + q.PageSize
+	if to > len(hits) {
+		to = len(hits)
+	}
+	resp.Hits = make([]domain.SearchHit, 0, to-from)
+	for _, s := range hits[from:to] {
+		resp.Hits = append(resp.Hits, toHit(s.doc, terms))
+	}
+	return resp, nil
+}
+
+// Suggest 按前缀联想帖子标题，票数高的优先
+func (idx *Index) Suggest(_ context.Context, prefix string, size int) ([]string, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []string{}, nil
+	}
+
+	idx.mu.RLock()
+	var matched []*document
+	for _, d := range idx.docs {
+		if d.Type == domain.SearchTypePost && strings.HasPrefix(strings.ToLower(d.Title), prefix) {
+			matched = append(matched, d)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sortHitsByVotes(matched)
+	titles := make([]string, 0, size)
+	seen := make(map[string]struct{}, size)
+	for _, d := range matched {
+		if len(titles) >= size {
+			break
+		}
+		if _, ok := seen[d.Title]; ok {
+			continue
+		}
+		seen[d.Title] = struct{}{}
+		titles = append(titles, d.Title)
+	}
+	return titles, nil
+}
+
+// RelatedPostIDs 取源帖子中 TF-IDF 最高的若干检索词作为查询，近似 ES 的 more_like_this
+func (idx *Index) RelatedPostIDs(_ context.Context, postID string, communityID int64, size int) ([]string, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	src, ok := idx.docs["p:"+postID]
+	if !ok {
+		return []string{}, nil
+	}
+
+	tf := termFrequencies(src.Title + " " + src.Content)
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	candidates := make([]weighted, 0, len(tf))
+	for t, n := range tf {
+		candidates = append(candidates, weighted{t, float64(n) * idx.idf(t)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].weight != candidates[j].weight {
+			return candidates[i].weight > candidates[j].weight
+		}
+		return candidates[i].term < candidates[j].term
+	})
+	if len(candidates) > relatedTerms {
+		candidates = candidates[:relatedTerms]
+	}
+	terms := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		terms = append(terms, c.term)
+	}
+
+	scores := idx.score(terms, func(d *document) bool {
+		return d.Type == domain.SearchTypePost &&
+			d.PostID != postID &&
+			d.Status == 1 &&
+			(communityID <= 0 || d.CommunityID == communityID)
+	})
+	sortHits(scores, domain.SearchSortRelevance)
+
+	ids := make([]string, 0, size)
+	for _, s := range scores {
+		if len(ids) >= size {
+			break
+		}
+		ids = append(ids, s.doc.PostID)
+	}
+	return ids, nil
+}
+
+// score 计算命中任一检索词且满足 keep 的文档得分，调用方持有读锁
+// 得分为各检索词 idf 与饱和词频之积的和，标题词频按 titleBoost 加权
+func (idx *Index) score(terms []string, keep func(d *document) bool) []scored {
+	totals := make(map[string]float64)
+	for _, t := range terms {
+		docs, ok := idx.postings[t]
+		if !ok {
+			continue
+		}
+		idf := idx.idf(t)
+		for key, p := range docs {
+			w := titleBoost*saturate(p.title) + saturate(p.content)
+			totals[key] += idf * w
+		}
+	}
+
+	result := make([]scored, 0, len(totals))
+	for key, s := range totals {
+		d := idx.docs[key]
+		if d != nil && keep(d) {
+			result = append(result, scored{doc: d, score: s})
+		}
+	}
+	return result
+}
+
+// idf 检索词的逆文档频率（BM25 形式），调用方持有读锁
+func (idx *Index) idf(term string) float64 {
+	n := float64(len(idx.docs))
+	df := float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// saturate 词频饱和，避免同一个词重复出现过度拉高得分
+func saturate(tf int) float64 {
+	if tf <= 0 {
+		return 0
+	}
+	return float64(tf) / (float64(tf) + 1)
+}
+
+func uniqueTerms(text string) []string {
+	tf := termFrequencies(text)
+	terms := make([]string, 0, len(tf))
+	for t := range tf {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+func matchType(d *document, typ string) bool {
+	switch typ {
+	case domain.SearchTypeAll:
+		return true
+	case domain.SearchTypeRemark:
+		return d.Type == domain.SearchTypeRemark
+	default:
+		return d.Type == domain.SearchTypePost
+	}
+}
+
+func matchFilters(d *document, q *domain.SearchQuery) bool {
+	if q.AuthorID > 0 && d.AuthorID != q.AuthorID {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && d.CreatedAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && !d.CreatedAt.Before(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+func matchCommunity(d *document, ids []int64) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, id := range ids {
+		if d.CommunityID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortHits 按排序方式排序，最后以文档键兜底保证分页稳定
+func sortHits(hits []scored, mode string) {
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		switch mode {
+		case domain.SearchSortNew:
+		case domain.SearchSortTop:
+			if a.doc.VoteCount != b.doc.VoteCount {
+				return a.doc.VoteCount > b.doc.VoteCount
+			}
+		default:
+			if a.score != b.score {
+				return a.score > b.score
+			}
+		}
+		if !a.doc.CreatedAt.Equal(b.doc.CreatedAt) {
+			return a.doc.CreatedAt.After(b.doc.CreatedAt)
+		}
+		return a.doc.key() < b.doc.key()
+	})
+}
+
+func sortHitsByVotes(docs []*document) {
+	sort.Slice(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		if a.VoteCount != b.VoteCount {
+			return a.VoteCount > b.VoteCount
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.key() < b.key()
+	})
+}
+
+func buildFacets(counts map[int64]int64) []domain.CommunityFacet {
+	facets := make([]domain.CommunityFacet, 0, len(counts))
+	for id, n := range counts {
+		facets = append(facets, domain.CommunityFacet{CommunityID: id, Count: n})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].CommunityID < facets[j].CommunityID
+	})
+	if len(facets) > communityFacetSize {
+		facets = facets[:communityFacetSize]
+	}
+	return facets
+}
+
+func toHit(d *document, terms []string) domain.SearchHit {
+	hit := domain.SearchHit{
+		Type:        d.Type,
+		RemarkID:    d.RemarkID,
+		PostID:      d.PostID,
+		AuthorID:    d.AuthorID,
+		CommunityID: d.CommunityID,
+		Title:       d.Title,
+		Content:     d.Content,
+		Status:      d.Status,
+		VoteCount:   d.VoteCount,
+		CreatedAt:   d.CreatedAt,
+	}
+	if hl, ok := highlight(d.Title, terms, 0); ok {
+		hit.HighlightTitle = []string{hl}
+	}
+	if hl, ok := highlight(d.Content, terms, fragmentSize); ok {
+		hit.HighlightContent = []string{hl}
+	}
+	return hit
+}
+
+// highlight 用高亮标签包裹 text 中命中检索词的部分
+// maxLen 大于 0 时只截取首个命中附近 maxLen 个字符的片段；没有命中时返回 false
+func highlight(text string, terms []string, maxLen int) (string, bool) {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, t := range terms {
+		tr := []rune(t)
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if !hasRunesAt(lower, tr, i) || !isTokenBoundary(lower, i, len(tr)) {
+				continue
+			}
+			for k := i; k < i+len(tr); k++ {
+				marked[k] = true
+			}
+			if first < 0 || i < first {
+				first = i
+			}
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if maxLen > 0 && len(runes) > maxLen {
+		start = first - maxLen/4
+		if start < 0 {
+			start = 0
+		}
+		end = start + maxLen
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxLen
+		}
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		if marked[i] && (i == start || !marked[i-1]) {
+			b.WriteString(highlightPre)
+		}
+		b.WriteRune(runes[i])
+		if marked[i] && (i == end-1 || !marked[i+1]) {
+			b.WriteString(highlightPost)
+		}
+	}
+	return b.String(), true
+}
+
+func hasRunesAt(text, term []rune, at int) bool {
+	for k, r := range term {
+		if text[at+k] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// isTokenBoundary 拉丁词必须整词命中，避免 "go" 高亮 "good" 的前缀；CJK 字符本身即是边界
+func isTokenBoundary(text []rune, at, n int) bool {
+	isWord := func(r rune) bool { return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r)) }
+	if at > 0 && isWord(text[at]) && isWord(text[at-1]) {
+		return false
+	}
+	end := at + n
+	if end < len(text) && isWord(text[end-1]) && isWord(text[end]) {
+		return false
+	}
+	return true
+}
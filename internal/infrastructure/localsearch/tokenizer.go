@@ -0,0 +1,69 @@
+package localsearch
+
+import (
+	"unicode"
+)
+
+// tokenize 把文本切分为检索词
+//   - 汉字、假名、谚文等 CJK 字符没有空格分词，按相邻两字切分（bigram），单字成段时保留单字，
+//     与 Elasticsearch 内置 cjk 分析器的策略一致，不依赖词典即可获得可用的召回
+//   - 字母和数字按连续片段切分并转为小写
+//   - 其余字符（空白、标点）视为分隔符
+func tokenize(text string) []string {
+	var (
+		tokens []string
+		word   []rune // 当前字母数字片段
+		cjk    []rune // 当前 CJK 片段
+	)
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// isCJK 判断字符是否属于需要按字切分的 CJK 文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// termFrequencies 统计文本中每个检索词出现的次数
+func termFrequencies(text string) map[string]int {
+	tf := make(map[string]int)
+	for _, t := range tokenize(text) {
+		tf[t]++
+	}
+	return tf
+}
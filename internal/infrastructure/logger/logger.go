@@ -2,9 +2,11 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -12,8 +14,25 @@ import (
 	"bluebell/internal/config"
 )
 
+// level 全局日志级别，配置热更新时原子切换，无需重建 Logger
+var level = zap.NewAtomicLevelAt(zap.DebugLevel)
+
 // Init 初始化 Logger
 func Init(cfg *config.Config, mode string) error {
+	if err := SetLevel(cfg.Log.Level); err != nil {
+		return err
+	}
+	config.Subscribe(func(old, new *config.Config) {
+		if new.Log == nil || (old.Log != nil && old.Log.Level == new.Log.Level) {
+			return
+		}
+		if err := SetLevel(new.Log.Level); err != nil {
+			zap.L().Error("apply log level failed", zap.Error(err))
+			return
+		}
+		zap.L().Info("log level changed", zap.String("level", level.String()))
+	})
+
 	writeSyncer := getWriteSyncer(cfg.Log.FileName, cfg.Log.MaxSize, cfg.Log.MaxAge, cfg.Log.MaxBackups)
 
 	// Redirect std log (log.Printf/Println) to file instead of stderr
@@ -30,7 +49,7 @@ func Init(cfg *config.Config, mode string) error {
 	fileCore := zapcore.NewCore(
 		zapcore.NewJSONEncoder(encoderConfig),
 		writeSyncer,
-		zap.DebugLevel,
+		level,
 	)
 	cores = append(cores, fileCore)
 
@@ -39,7 +58,7 @@ func Init(cfg *config.Config, mode string) error {
 		consoleCore := zapcore.NewCore(
 			zapcore.NewConsoleEncoder(encoderConfig),
 			os.Stdout,
-			zap.DebugLevel,
+			level,
 		)
 		cores = append(cores, consoleCore)
 	}
@@ -50,9 +69,68 @@ func Init(cfg *config.Config, mode string) error {
 	return nil
 }
 
-// WithContext 兼容接口：直接返回全局 Logger
-func WithContext(_ context.Context) *zap.Logger {
-	return zap.L()
+// SetLevel 切换日志级别，空字符串表示 debug
+func SetLevel(l string) error {
+	if l == "" {
+		level.SetLevel(zap.DebugLevel)
+		return nil
+	}
+	lvl, err := zapcore.ParseLevel(l)
+	if err != nil {
+		return fmt.Errorf("parse log level failed: %w", err)
+	}
+	level.SetLevel(lvl)
+	return nil
+}
+
+// 上下文中保存请求级 Logger 和请求 ID 的键
+type (
+	loggerCtxKey    struct{}
+	requestIDCtxKey struct{}
+)
+
+// NewContext 将请求级 Logger 存入上下文，之后 WithContext(ctx) 取到的都是它
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// ContextWithRequestID 将请求 ID 存入上下文，发布消息时据此透传给消费者
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, requestID)
+}
+
+// RequestIDFromContext 取出上下文中的请求 ID，没有时返回空串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// WithContext 返回上下文对应的 Logger
+// 优先使用中间件或消费者存入的请求级 Logger；否则基于全局 Logger 补充请求 ID 和 trace ID
+func WithContext(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return zap.L()
+	}
+	if l, ok := ctx.Value(loggerCtxKey{}).(*zap.Logger); ok {
+		return l
+	}
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return zap.L()
+	}
+	return zap.L().With(fields...)
+}
+
+// ContextFields 从上下文中提取用于关联日志的字段：请求 ID 和 trace ID
+func ContextFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+	}
+	return fields
 }
 
 func getWriteSyncer(filename string, maxsize int, maxage int, maxbackups int) zapcore.WriteSyncer {
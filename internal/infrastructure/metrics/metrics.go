@@ -0,0 +1,139 @@
+// Package metrics 应用指标定义，注册到 Prometheus 默认注册表，由 /metrics 端点暴露
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ========== HTTP ==========
+
+var (
+	// HTTPRequestDuration 请求耗时，route 为路由模板（如 /api/v1/post/:id），未匹配路由统一记为 unmatched
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bluebell_http_request_duration_seconds",
+		Help:    "HTTP request latency by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPErrors 业务错误响应数，category 取自 render.classifyError
+	HTTPErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_http_errors_total",
+		Help: "HTTP error responses by error category.",
+	}, []string{"category"})
+
+	// RateLimitRejections 被限流拒绝的请求数，limiter 为 global（全局令牌桶）或 client（按客户端）
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_ratelimit_rejections_total",
+		Help: "Requests rejected by rate limiters.",
+	}, []string{"limiter"})
+)
+
+// ========== MySQL ==========
+
+var (
+	// DBQueryDuration GORM 语句耗时，operation 为 create/query/update/delete/row/raw
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bluebell_db_query_duration_seconds",
+		Help:    "GORM statement latency by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// DBQueryErrors GORM 语句失败数，记录不存在不计入
+	DBQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_db_query_errors_total",
+		Help: "Failed GORM statements by operation and table.",
+	}, []string{"operation", "table"})
+)
+
+// ========== 缓存 ==========
+
+var (
+	// PostDetailCacheLookups 帖子详情缓存查找结果，按帖子计数；result 为 hit、miss、negative（命中不存在占位值）或 error（Redis 出错降级查库）
+	PostDetailCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_post_detail_cache_lookups_total",
+		Help: "Post detail cache lookups by result.",
+	}, []string{"result"})
+
+	// PostFilterRejections 被布隆过滤器判定为不存在、未查库直接返回的帖子 ID 数
+	PostFilterRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bluebell_post_filter_rejections_total",
+		Help: "Post IDs rejected by the Bloom filter before reaching the database.",
+	})
+
+	// PostFilterAddFailures 新帖子写入布隆过滤器失败的次数，每次失败都会触发一次过滤器重建
+	PostFilterAddFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bluebell_post_filter_add_failures_total",
+		Help: "Failed Bloom filter insertions of new post IDs; each one schedules a filter rebuild.",
+	})
+
+	// LocalCacheLookups 进程内缓存查找结果，result 为 hit 或 miss
+	LocalCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_local_cache_lookups_total",
+		Help: "In-process cache lookups by cache name and result.",
+	}, []string{"cache", "result"})
+
+	// LocalCacheEvictions 进程内缓存因容量不足淘汰的条目数
+	LocalCacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_local_cache_evictions_total",
+		Help: "In-process cache entries evicted for capacity by cache name.",
+	}, []string{"cache"})
+
+	// LocalCacheEntries 进程内缓存当前条目数
+	LocalCacheEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bluebell_local_cache_entries",
+		Help: "Current in-process cache entries by cache name.",
+	}, []string{"cache"})
+)
+
+// ========== 消息队列 ==========
+
+var (
+	// MQPublished 发布结果，result 为 ok（Broker 已确认）、nack（Broker 拒绝或等待确认失败）或 error（发送失败）
+	MQPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_mq_published_total",
+		Help: "Messages published by exchange and result.",
+	}, []string{"exchange", "result"})
+
+	// MQConsumed 投递给消费者的消息数
+	MQConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_mq_consumed_total",
+		Help: "Messages delivered to consumers by queue.",
+	}, []string{"queue"})
+
+	// MQConsumeFailures 消费失败数，action 为 retry（进入重试或死信）或 nack
+	MQConsumeFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bluebell_mq_consume_failures_total",
+		Help: "Messages that failed processing by queue and action.",
+	}, []string{"queue", "action"})
+
+	// MQConsumeLag 消息从发布到投递给消费者的延迟
+	MQConsumeLag = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bluebell_mq_consume_lag_seconds",
+		Help:    "Delay between publishing and delivering a message.",
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+	}, []string{"queue"})
+)
+
+// ========== 热度刷新任务 ==========
+
+var (
+	// HotScoreRefreshDuration 单次全量刷新耗时
+	HotScoreRefreshDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "bluebell_hot_score_refresh_duration_seconds",
+		Help:    "Duration of a hot score refresh run.",
+		Buckets: []float64{.1, .5, 1, 5, 10, 30, 60, 120, 300},
+	})
+
+	// HotScoreRefreshedPosts 最近一次刷新处理的帖子数
+	HotScoreRefreshedPosts = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "bluebell_hot_score_refreshed_posts",
+		Help: "Number of posts processed by the last hot score refresh run.",
+	})
+
+	// HotScoreRefreshErrors 刷新过程中失败的批次数
+	HotScoreRefreshErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bluebell_hot_score_refresh_errors_total",
+		Help: "Hot score refresh batches that failed.",
+	})
+)
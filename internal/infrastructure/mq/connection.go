@@ -3,6 +3,8 @@ package mq
 import (
 	"fmt"
 	"log"
+	neturl "net/url"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -17,8 +19,56 @@ const (
 	ExchangeSearch   = "search.exchange"
 	QueueSearch      = "search.queue"
 	RoutingKeySearch = "search.sync"
+
+	// 死信交换机与死信队列：超过最大重试次数或无法解析的消息最终落在这里
+	ExchangeVoteDLX   = "vote.dlx.exchange"
+	QueueVoteDLQ      = "vote.dlq"
+	ExchangeSearchDLX = "search.dlx.exchange"
+	QueueSearchDLQ    = "search.dlq"
+)
+
+// Topology 单个消费者的完整拓扑：业务交换机/队列 + 重试队列 + 死信交换机/队列
+type Topology struct {
+	Name       string // 消费者名称，供命令行工具引用
+	Exchange   string
+	Queue      string
+	RoutingKey string
+	DLX        string
+	DLQ        string
+}
+
+var (
+	VoteTopology = Topology{
+		Name: "vote", Exchange: ExchangeVote, Queue: QueueVote, RoutingKey: RoutingKeyVote,
+		DLX: ExchangeVoteDLX, DLQ: QueueVoteDLQ,
+	}
+	SearchTopology = Topology{
+		Name: "search", Exchange: ExchangeSearch, Queue: QueueSearch, RoutingKey: RoutingKeySearch,
+		DLX: ExchangeSearchDLX, DLQ: QueueSearchDLQ,
+	}
 )
 
+// Topologies 返回所有消费者拓扑
+func Topologies() []Topology {
+	return []Topology{VoteTopology, SearchTopology}
+}
+
+// LookupTopology 根据消费者名称查找拓扑
+func LookupTopology(name string) (Topology, bool) {
+	for _, t := range Topologies() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Topology{}, false
+}
+
+// RetryQueue 返回指定延迟对应的重试队列名
+// 队列名带上延迟时长：修改退避配置后会声明新的队列，不会与已存在队列的 TTL 参数冲突
+func (t Topology) RetryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", t.Queue, delay)
+}
+
 // ==================== 手动挡工厂方法 ====================
 
 // Dial 建立纯粹的 TCP 连接 (物理管道)
@@ -27,37 +77,60 @@ func Dial(url string) (*amqp.Connection, error) {
 	if err != nil {
 		return nil, fmt.Errorf("RabbitMQ 连接失败: %w", err)
 	}
-	log.Printf("RabbitMQ 连接已建立: %s", url)
+	log.Printf("RabbitMQ 连接已建立: %s", redactURL(url))
 	return conn, nil
 }
 
+// redactURL 隐藏连接地址中的密码，用于日志输出
+func redactURL(raw string) string {
+	u, err := neturl.Parse(raw)
+	if err != nil {
+		return "<invalid url>"
+	}
+	return u.Redacted()
+}
+
 // SetupResources 在指定的信道上声明交换机和队列
 // 一次性装修工作，装修完信道即可关闭
-func SetupResources(ch *amqp.Channel) error {
-	exchanges := []struct{ Name, Kind string }{
-		{ExchangeVote, "direct"},
-		{ExchangeSearch, "direct"},
-	}
-	for _, ex := range exchanges {
-		if err := ch.ExchangeDeclare(ex.Name, ex.Kind, true, false, false, false, nil); err != nil {
-			return fmt.Errorf("声明交换机 %s 失败: %w", ex.Name, err)
+//
+// 每个消费者除业务队列外还会声明：
+//   - 重试队列：每一级退避延迟一个队列，消息 TTL 到期后经死信路由回到业务交换机
+//   - 死信交换机 + 死信队列：按业务队列名路由，存放最终失败的消息
+func SetupResources(ch *amqp.Channel, policy RetryPolicy) error {
+	for _, t := range Topologies() {
+		for _, ex := range []string{t.Exchange, t.DLX} {
+			if err := ch.ExchangeDeclare(ex, "direct", true, false, false, false, nil); err != nil {
+				return fmt.Errorf("声明交换机 %s 失败: %w", ex, err)
+			}
 		}
-	}
 
-	queues := []struct {
-		Name     string
-		Exchange string
-		RK       string
-	}{
-		{QueueVote, ExchangeVote, RoutingKeyVote},
-		{QueueSearch, ExchangeSearch, RoutingKeySearch},
-	}
-	for _, q := range queues {
-		if _, err := ch.QueueDeclare(q.Name, true, false, false, false, nil); err != nil {
-			return fmt.Errorf("声明队列 %s 失败: %w", q.Name, err)
+		queues := []struct {
+			Name     string
+			Exchange string
+			RK       string
+		}{
+			{t.Queue, t.Exchange, t.RoutingKey},
+			{t.DLQ, t.DLX, t.Queue},
+		}
+		for _, q := range queues {
+			if _, err := ch.QueueDeclare(q.Name, true, false, false, false, nil); err != nil {
+				return fmt.Errorf("声明队列 %s 失败: %w", q.Name, err)
+			}
+			if err := ch.QueueBind(q.Name, q.RK, q.Exchange, false, nil); err != nil {
+				return fmt.Errorf("绑定队列 %s 失败: %w", q.Name, err)
+			}
 		}
-		if err := ch.QueueBind(q.Name, q.RK, q.Exchange, false, nil); err != nil {
-			return fmt.Errorf("绑定队列 %s 失败: %w", q.Name, err)
+
+		// 重试队列不绑定交换机，由消费者通过默认交换机直接投递
+		for _, delay := range policy.Delays() {
+			args := amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    t.Exchange,
+				"x-dead-letter-routing-key": t.RoutingKey,
+			}
+			if _, err := ch.QueueDeclare(t.RetryQueue(delay), true, false, false, false, args); err != nil {
+				return fmt.Errorf("声明重试队列 %s 失败: %w", t.RetryQueue(delay), err)
+			}
 		}
 	}
 
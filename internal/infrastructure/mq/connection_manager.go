@@ -0,0 +1,250 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"bluebell/internal/config"
+	"bluebell/internal/domain"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrNotConnected 连接已断开、正在重连，调用方应快速失败或稍后重试
+	ErrNotConnected = domain.ErrBrokerDisconnected
+	// ErrManagerClosed 连接管理器已关闭
+	ErrManagerClosed = errors.New("rabbitmq: 连接管理器已关闭")
+)
+
+// ConnectionManager 自动重连的连接管理器
+// 通过 NotifyClose 监听物理连接，断开后按指数退避重连，并在新连接上重新执行 SetupResources。
+// 信道不在这里缓存：发布者和消费者各自按需从当前连接打开信道，断线后再重新打开
+type ConnectionManager struct {
+	url      string
+	policy   RetryPolicy
+	minDelay time.Duration
+	maxDelay time.Duration
+
+	mu    sync.RWMutex
+	conn  *amqp.Connection
+	ready chan struct{} // 连接可用时关闭；断线后换成新的未关闭 channel
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewConnectionManager 建立首个连接并声明资源，随后在后台监听断线
+// 首次连接失败不返回错误：与断线一样在后台按退避重连，期间 IsConnected 为 false，
+// 发布快速失败（发件箱消息留待重连后投递），消费者阻塞在 WaitConnected 上
+func NewConnectionManager(url string, policy RetryPolicy, minDelay, maxDelay time.Duration) *ConnectionManager {
+	m := &ConnectionManager{
+		url:      url,
+		policy:   policy,
+		minDelay: minDelay,
+		maxDelay: maxDelay,
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	conn, err := m.connect()
+	if err != nil {
+		log.Printf("RabbitMQ 首次连接失败，后台重连: %v", err)
+	} else {
+		m.setConn(conn)
+	}
+
+	m.wg.Add(1)
+	go m.watch(conn)
+	return m
+}
+
+// NewConnectionManagerFromConfig 从配置创建连接管理器
+func NewConnectionManagerFromConfig(cfg *config.Config, policy RetryPolicy) *ConnectionManager {
+	return NewConnectionManager(cfg.RabbitMQ.URL, policy, cfg.RabbitMQ.ReconnectMinDelay, cfg.RabbitMQ.ReconnectMaxDelay)
+}
+
+// connect 建立连接并用临时信道声明交换机和队列
+func (m *ConnectionManager) connect() (*amqp.Connection, error) {
+	conn, err := Dial(m.url)
+	if err != nil {
+		return nil, err
+	}
+	setupCh, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("创建初始化信道失败: %w", err)
+	}
+	defer setupCh.Close()
+	if err := SetupResources(setupCh, m.policy); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (m *ConnectionManager) setConn(conn *amqp.Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conn = conn
+	close(m.ready)
+}
+
+// watch 监听连接关闭事件并负责重连，直到管理器关闭；conn 为 nil 表示首次连接失败，直接进入重连
+func (m *ConnectionManager) watch(conn *amqp.Connection) {
+	defer m.wg.Done()
+	if conn == nil {
+		if conn = m.reconnect(); conn == nil {
+			return
+		}
+		m.setConn(conn)
+	}
+	for {
+		// 连接已关闭时注册的 NotifyClose 会立即被关闭，不会漏掉断线事件
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		select {
+		case <-m.done:
+			return
+		case amqpErr := <-closed:
+			select {
+			case <-m.done:
+				return
+			default:
+			}
+			log.Printf("RabbitMQ 连接断开: %v，开始重连", amqpErr)
+		}
+
+		m.mu.Lock()
+		m.conn = nil
+		m.ready = make(chan struct{})
+		m.mu.Unlock()
+
+		conn = m.reconnect()
+		if conn == nil {
+			return
+		}
+		m.setConn(conn)
+	}
+}
+
+// reconnect 按指数退避不断重连，管理器关闭时返回 nil
+func (m *ConnectionManager) reconnect() *amqp.Connection {
+	delay := m.minDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-m.done:
+			return nil
+		case <-time.After(delay):
+		}
+
+		conn, err := m.connect()
+		if err == nil {
+			select {
+			case <-m.done:
+				_ = conn.Close()
+				return nil
+			default:
+			}
+			log.Printf("RabbitMQ 重连成功 (attempt: %d)", attempt)
+			return conn
+		}
+		log.Printf("RabbitMQ 重连失败 (attempt: %d)，%v 后重试: %v", attempt, delay, err)
+
+		delay *= 2
+		if delay > m.maxDelay {
+			delay = m.maxDelay
+		}
+	}
+}
+
+// IsConnected 当前连接是否可用
+func (m *ConnectionManager) IsConnected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conn != nil && !m.conn.IsClosed()
+}
+
+// Channel 在当前连接上打开一个新信道，断线期间快速失败并返回 ErrNotConnected
+func (m *ConnectionManager) Channel() (*amqp.Channel, error) {
+	m.mu.RLock()
+	conn := m.conn
+	m.mu.RUnlock()
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("创建信道失败: %w", err)
+	}
+	return ch, nil
+}
+
+// WaitConnected 阻塞直到连接可用、ctx 取消或管理器关闭
+func (m *ConnectionManager) WaitConnected(ctx context.Context) error {
+	m.mu.RLock()
+	ready := m.ready
+	m.mu.RUnlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.done:
+		return ErrManagerClosed
+	}
+}
+
+// RunWithChannel 在独立信道上运行 fn，并在信道或连接中断后自动恢复
+// fn 应阻塞直到 ctx 取消或信道失效；每次恢复都会打开一个新信道重新运行 fn。
+// ctx 取消或管理器关闭时返回 nil
+func (m *ConnectionManager) RunWithChannel(ctx context.Context, name string, fn func(ctx context.Context, ch *amqp.Channel) error) error {
+	for {
+		if err := m.WaitConnected(ctx); err != nil {
+			return nil
+		}
+
+		ch, err := m.Channel()
+		if err == nil {
+			err = fn(ctx, ch)
+			_ = ch.Close()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("%s 信道中断，等待恢复: %v", name, err)
+
+		// 连接仍可用但信道反复失败时（如队列被误删）避免空转
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.done:
+			return nil
+		case <-time.After(m.minDelay):
+		}
+	}
+}
+
+// Close 停止重连并关闭当前连接
+func (m *ConnectionManager) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+
+		m.mu.Lock()
+		conn := m.conn
+		m.conn = nil
+		m.mu.Unlock()
+		if conn != nil {
+			err = conn.Close()
+		}
+		m.wg.Wait()
+		log.Println("RabbitMQ 连接管理器已关闭")
+	})
+	return err
+}
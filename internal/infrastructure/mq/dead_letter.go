@@ -0,0 +1,116 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter 死信队列中的一条消息
+type DeadLetter struct {
+	MessageID string    `json:"message_id,omitempty"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	DeadAt    string    `json:"dead_at"`
+	Timestamp time.Time `json:"timestamp"`
+	Body      string    `json:"body"`
+}
+
+func toDeadLetter(d amqp.Delivery) DeadLetter {
+	lastErr, _ := d.Headers[HeaderLastError].(string)
+	deadAt, _ := d.Headers[HeaderDeadAt].(string)
+	return DeadLetter{
+		MessageID: d.MessageId,
+		Attempts:  retryCount(d),
+		LastError: lastErr,
+		DeadAt:    deadAt,
+		Timestamp: d.Timestamp,
+		Body:      string(d.Body),
+	}
+}
+
+// InspectDeadLetters 查看死信队列头部最多 limit 条消息，不会移除消息
+func InspectDeadLetters(ch *amqp.Channel, t Topology, limit int) ([]DeadLetter, error) {
+	var (
+		letters    []DeadLetter
+		deliveries []amqp.Delivery
+	)
+	// 读完后统一 nack 重新入队；边读边入队会反复读到同一条消息
+	defer func() {
+		for _, d := range deliveries {
+			_ = d.Nack(false, true)
+		}
+	}()
+
+	for len(letters) < limit {
+		d, ok, err := ch.Get(t.DLQ, false)
+		if err != nil {
+			return nil, fmt.Errorf("读取死信队列 %s 失败: %w", t.DLQ, err)
+		}
+		if !ok {
+			break
+		}
+		deliveries = append(deliveries, d)
+		letters = append(letters, toDeadLetter(d))
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters 将死信队列中最多 limit 条消息重新投递回业务交换机，并清零重试次数
+// 每条消息等 Broker 确认重新投递后才从死信队列移除，返回成功重放的消息数
+func ReplayDeadLetters(ctx context.Context, ch *amqp.Channel, t Topology, limit int) (int, error) {
+	if err := ch.Confirm(false); err != nil {
+		return 0, fmt.Errorf("开启 Publisher Confirms 失败: %w", err)
+	}
+
+	replayed := 0
+	for replayed < limit {
+		d, ok, err := ch.Get(t.DLQ, false)
+		if err != nil {
+			return replayed, fmt.Errorf("读取死信队列 %s 失败: %w", t.DLQ, err)
+		}
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for k, v := range d.Headers {
+			headers[k] = v
+		}
+		delete(headers, HeaderRetryCount)
+		delete(headers, HeaderDeadAt)
+
+		dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, t.Exchange, t.RoutingKey, false, false, amqp.Publishing{
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			ContentType:  d.ContentType,
+			Body:         d.Body,
+			Timestamp:    d.Timestamp,
+			MessageId:    d.MessageId,
+		})
+		if err != nil {
+			_ = d.Nack(false, true)
+			return replayed, fmt.Errorf("重放死信消息失败: %w", err)
+		}
+		if acked, err := dc.WaitContext(ctx); err != nil || !acked {
+			_ = d.Nack(false, true)
+			return replayed, fmt.Errorf("重放死信消息未被 Broker 确认: %v", err)
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, fmt.Errorf("确认死信消息失败: %w", err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// PurgeDeadLetters 清空死信队列，返回被删除的消息数
+func PurgeDeadLetters(ch *amqp.Channel, t Topology) (int, error) {
+	n, err := ch.QueuePurge(t.DLQ, false)
+	if err != nil {
+		return 0, fmt.Errorf("清空死信队列 %s 失败: %w", t.DLQ, err)
+	}
+	return n, nil
+}
@@ -1,93 +1,126 @@
 package mq
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"time"
 
-	"bluebell/internal/infrastructure/es"
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/logger"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
-// SyncConsumer ES 同步消费者
+// SyncConsumer 搜索同步消费者
+// 把搜索同步消息写入搜索索引，索引实现（ES 或嵌入式）由 domain.SearchIndexer 屏蔽
 type SyncConsumer struct {
-	ch     *amqp.Channel
-	client *es.Client
+	sub     domain.Subscriber
+	indexer domain.SearchIndexer
 }
 
 // NewSyncConsumer 创建一个新的搜索同步消费者
-func NewSyncConsumer(ch *amqp.Channel, esClient *es.Client) *SyncConsumer {
-	return &SyncConsumer{ch: ch, client: esClient}
+func NewSyncConsumer(sub domain.Subscriber, indexer domain.SearchIndexer) *SyncConsumer {
+	return &SyncConsumer{
+		sub:     sub,
+		indexer: indexer,
+	}
 }
 
-// Start 启动监听
+// Start 启动监听，阻塞直到 ctx 取消
+// 断线重连由订阅者负责，对消费者透明
 func (c *SyncConsumer) Start(ctx context.Context) error {
-	if err := c.ch.Qos(1, 0, false); err != nil {
-		return fmt.Errorf("设置 QoS 失败: %w", err)
-	}
-
-	msgs, err := c.ch.Consume(QueueSearch, "", false, false, false, false, nil)
+	deliveries, err := c.sub.Subscribe(ctx, QueueSearch, 1)
 	if err != nil {
-		return fmt.Errorf("消费队列 %s 失败: %w", QueueSearch, err)
+		return err
 	}
 
-	log.Printf("搜索同步消费者已启动: %s", QueueSearch)
+	logger.WithContext(ctx).Info("search sync consumer started", zap.String("queue", QueueSearch))
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case d, ok := <-msgs:
-			if !ok {
-				return fmt.Errorf("消费者通道已关闭")
-			}
-			if err := c.handleDelivery(ctx, d); err != nil {
-				log.Printf("处理搜索消息失败: %v", err)
-				_ = d.Nack(false, false)
-			} else {
-				_ = d.Ack(false)
+	for d := range deliveries {
+		// 从 Headers 提取 Trace 上下文，处理过程挂在发布方的链路下
+		msgCtx, span := startConsumeSpan(ctx, QueueSearch, d)
+		err := c.handleDelivery(msgCtx, d.Body())
+		if err != nil {
+			logger.WithContext(msgCtx).Error("handle search message failed", zap.Error(err))
+			if err := d.Retry(msgCtx, err); err != nil {
+				logger.WithContext(msgCtx).Error("retry search message failed", zap.Error(err))
 			}
+		} else {
+			_ = d.Ack(false)
 		}
+		endSpan(span, err)
 	}
+	return nil
 }
 
-func (c *SyncConsumer) handleDelivery(ctx context.Context, d amqp.Delivery) error {
-	// 从 Headers 提取 Trace 上下文
-
+func (c *SyncConsumer) handleDelivery(ctx context.Context, body []byte) error {
 	var msg SyncMessage
-	if err := json.Unmarshal(d.Body, &msg); err != nil {
-		log.Printf("解析搜索消息失败: %v", err)
-		_ = d.Nack(false, false)
-		return nil
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return domain.Permanent(fmt.Errorf("es_consumer: 解析搜索消息失败: %w", err))
+	}
+	if msg.DocType == DocTypeRemark {
+		return c.handleRemark(ctx, &msg)
 	}
 
 	switch msg.Action {
-	case "delete":
-		if err := c.client.DeleteDocument(ctx, es.IndexPost, msg.PostID); err != nil {
-			return fmt.Errorf("es_consumer: 删除ES文档失败 (post_id: %s): %w", msg.PostID, err)
+	case "delete", "purge":
+		if err := c.indexer.DeletePost(ctx, msg.PostID); err != nil {
+			return fmt.Errorf("es_consumer: 删除搜索文档失败 (post_id: %s): %w", msg.PostID, err)
 		}
-	default:
-		doc := map[string]interface{}{
-			"post_id":      msg.PostID,
-			"author_id":    msg.AuthorID,
-			"community_id": msg.CommunityID,
-			"post_title":   msg.PostTitle,
-			"content":      msg.Content,
-			"status":       msg.Status,
-			"created_at":   msg.CreatedAt,
+		if msg.Action == "purge" {
+			if err := c.indexer.DeletePostRemarks(ctx, msg.PostID); err != nil {
+				return fmt.Errorf("es_consumer: 删除帖子评论文档失败 (post_id: %s): %w", msg.PostID, err)
+			}
+		}
+	case "votes":
+		if err := c.indexer.UpdatePostVotes(ctx, msg.PostID, msg.VoteCount); err != nil {
+			return fmt.Errorf("es_consumer: 更新搜索票数失败 (post_id: %s): %w", msg.PostID, err)
 		}
-		body, err := json.Marshal(doc)
+	default:
+		createdAt, err := time.Parse(time.RFC3339, msg.CreatedAt)
 		if err != nil {
-			return fmt.Errorf("序列化文档失败: %w", err)
+			return domain.Permanent(fmt.Errorf("es_consumer: 解析创建时间失败 (post_id: %s): %w", msg.PostID, err))
 		}
-		if err := c.client.IndexDocument(ctx, es.IndexPost, msg.PostID, bytes.NewReader(body)); err != nil {
-			return fmt.Errorf("es_consumer: 索引ES文档失败 (post_id: %s): %w", msg.PostID, err)
+		if err := c.indexer.IndexPost(ctx, &domain.PostDocument{
+			PostID:      msg.PostID,
+			AuthorID:    msg.AuthorID,
+			CommunityID: msg.CommunityID,
+			Title:       msg.PostTitle,
+			Content:     msg.Content,
+			Status:      msg.Status,
+			VoteCount:   msg.VoteCount,
+			CreatedAt:   createdAt,
+		}); err != nil {
+			return fmt.Errorf("es_consumer: 索引搜索文档失败 (post_id: %s): %w", msg.PostID, err)
 		}
 	}
 
-	_ = d.Ack(false)
+	return nil
+}
+
+// handleRemark 同步评论文档到评论索引
+func (c *SyncConsumer) handleRemark(ctx context.Context, msg *SyncMessage) error {
+	if msg.Action == "delete" {
+		if err := c.indexer.DeleteRemark(ctx, msg.RemarkID); err != nil {
+			return fmt.Errorf("es_consumer: 删除搜索评论失败 (remark_id: %s): %w", msg.RemarkID, err)
+		}
+		return nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, msg.CreatedAt)
+	if err != nil {
+		return domain.Permanent(fmt.Errorf("es_consumer: 解析创建时间失败 (remark_id: %s): %w", msg.RemarkID, err))
+	}
+	if err := c.indexer.IndexRemark(ctx, &domain.RemarkDocument{
+		RemarkID:    msg.RemarkID,
+		PostID:      msg.PostID,
+		AuthorID:    msg.AuthorID,
+		CommunityID: msg.CommunityID,
+		Content:     msg.Content,
+		CreatedAt:   createdAt,
+	}); err != nil {
+		return fmt.Errorf("es_consumer: 索引搜索评论失败 (remark_id: %s): %w", msg.RemarkID, err)
+	}
 	return nil
 }
@@ -0,0 +1,78 @@
+package mq
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/domain/event"
+	"bluebell/internal/infrastructure/eventbus"
+	"bluebell/internal/infrastructure/localsearch"
+)
+
+// captureOutbox 只记录写入的发件箱消息
+type captureOutbox struct {
+	domain.OutboxRepository
+	msgs []*entity.OutboxMessage
+}
+
+func (o *captureOutbox) Add(_ context.Context, msg *entity.OutboxMessage) error {
+	o.msgs = append(o.msgs, msg)
+	return nil
+}
+
+func TestSyncConsumer_PostDeletedPurgesRemarks(t *testing.T) {
+	ctx := context.Background()
+	idx, err := localsearch.Open(filepath.Join(t.TempDir(), "index.json"))
+	if err != nil {
+		t.Fatalf("localsearch.Open() error = %v", err)
+	}
+	defer idx.Close()
+
+	outbox := &captureOutbox{}
+	bus := eventbus.New()
+	RegisterOutboxBridge(bus, outbox)
+	consumer := NewSyncConsumer(nil, idx)
+
+	// 事件经桥接写入发件箱，再逐条交给消费者，模拟 OutboxRelay → MQ → 消费者的完整链路
+	publish := func(events ...event.Event) {
+		t.Helper()
+		outbox.msgs = nil
+		if err := bus.Publish(ctx, events...); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		for _, m := range outbox.msgs {
+			if err := consumer.handleDelivery(ctx, m.Payload); err != nil {
+				t.Fatalf("handleDelivery(%s) error = %v", m.Payload, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	publish(
+		event.PostCreated{PostSnapshot: event.PostSnapshot{PostID: 1, CommunityID: 1, Title: "Go 语言", Content: "并发", Status: 1, CreatedAt: now}},
+		event.PostCreated{PostSnapshot: event.PostSnapshot{PostID: 2, CommunityID: 1, Title: "Rust", Content: "所有权", Status: 1, CreatedAt: now}},
+		event.RemarkAdded{RemarkSnapshot: event.RemarkSnapshot{RemarkID: 10, PostID: 1, CommunityID: 1, Content: "协程好用", CreatedAt: now}},
+		event.RemarkAdded{RemarkSnapshot: event.RemarkSnapshot{RemarkID: 11, PostID: 2, CommunityID: 1, Content: "协程呢", CreatedAt: now}},
+	)
+	res, _ := idx.Search(ctx, &domain.SearchQuery{Keyword: "协程", Type: domain.SearchTypeRemark})
+	if res.Total != 2 {
+		t.Fatalf("Search() before delete total = %d, want 2", res.Total)
+	}
+
+	publish(event.PostDeleted{PostID: 1, CommunityID: 1})
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "协程", Type: domain.SearchTypeAll})
+	if res.Total != 1 || res.Hits[0].RemarkID != "11" {
+		t.Errorf("Search() after delete = %+v, want only remark 11", res.Hits)
+	}
+
+	// 隐藏的帖子保留评论文档，恢复后仍可搜到
+	publish(event.PostHidden{PostID: 2})
+	res, _ = idx.Search(ctx, &domain.SearchQuery{Keyword: "协程", Type: domain.SearchTypeRemark})
+	if res.Total != 1 {
+		t.Errorf("Search() after hide total = %d, want 1", res.Total)
+	}
+}
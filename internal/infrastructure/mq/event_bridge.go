@@ -0,0 +1,156 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/domain/event"
+	"bluebell/internal/infrastructure/snowflake"
+)
+
+// OutboxBridge 领域事件到 MQ 的桥接
+// 把需要跨进程处理的领域事件转换为 MQ 消息写入发件箱，由 OutboxRelay 投递。
+// 事件在事务中发布时，发件箱消息与业务数据一同提交或回滚
+type OutboxBridge struct {
+	outboxRepo domain.OutboxRepository
+}
+
+// RegisterOutboxBridge 创建桥接并订阅相关事件
+func RegisterOutboxBridge(bus domain.EventBus, outboxRepo domain.OutboxRepository) *OutboxBridge {
+	b := &OutboxBridge{outboxRepo: outboxRepo}
+	// 帖子生命周期事件统一经 onPostChanged 转换为搜索同步消息
+	for _, name := range []string{
+		event.NamePostCreated, event.NamePostEdited, event.NamePostRestored,
+		event.NamePostDeleted, event.NamePostHidden,
+	} {
+		bus.Subscribe(name, b.onPostChanged)
+	}
+	for _, name := range []string{event.NameRemarkAdded, event.NameRemarkEdited, event.NameRemarkDeleted} {
+		bus.Subscribe(name, b.onRemarkChanged)
+	}
+	bus.Subscribe(event.NameVoteCast, b.onVoteCast)
+	return b
+}
+
+// enqueue 将消息写入发件箱
+func (b *OutboxBridge) enqueue(ctx context.Context, exchange, routingKey string, msg interface{}) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化发件箱消息失败: %w", err)
+	}
+	return b.outboxRepo.Add(ctx, &entity.OutboxMessage{
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Payload:    payload,
+		Headers:    injectOutboxHeaders(ctx),
+	})
+}
+
+// searchTimeLayout 搜索索引中时间字段的统一格式
+const searchTimeLayout = time.RFC3339
+
+// onPostChanged 帖子生命周期事件 → 搜索同步消息
+// 可见的帖子（创建、编辑、恢复）写入完整文档，不可见的帖子（删除、隐藏）从索引中移除；
+// 删除的帖子连同评论一起清理，隐藏的帖子保留评论文档以便恢复后可再次搜到
+func (b *OutboxBridge) onPostChanged(ctx context.Context, e event.Event) error {
+	var msg *SyncMessage
+	switch ev := e.(type) {
+	case event.PostCreated:
+		msg = indexMessage(ev.PostSnapshot)
+	case event.PostEdited:
+		msg = indexMessage(ev.PostSnapshot)
+	case event.PostRestored:
+		msg = indexMessage(ev.PostSnapshot)
+	case event.PostDeleted:
+		msg = deleteMessage(ev.PostID)
+		msg.Action = "purge"
+	case event.PostHidden:
+		msg = deleteMessage(ev.PostID)
+	default:
+		return fmt.Errorf("unexpected post event %s", e.EventName())
+	}
+	return b.enqueue(ctx, ExchangeSearch, RoutingKeySearch, msg)
+}
+
+// indexMessage 帖子索引消息；快照不含票数，索引端写入时保留文档已有的票数
+func indexMessage(p event.PostSnapshot) *SyncMessage {
+	return &SyncMessage{
+		PostID:      strconv.FormatInt(p.PostID, 10),
+		AuthorID:    p.AuthorID,
+		CommunityID: p.CommunityID,
+		PostTitle:   p.Title,
+		Content:     p.Content,
+		Status:      p.Status,
+		CreatedAt:   p.CreatedAt.Format(searchTimeLayout),
+		Action:      "index",
+	}
+}
+
+func deleteMessage(postID int64) *SyncMessage {
+	return &SyncMessage{
+		PostID: strconv.FormatInt(postID, 10),
+		Action: "delete",
+	}
+}
+
+// onRemarkChanged 评论生命周期事件 → 评论索引同步消息
+func (b *OutboxBridge) onRemarkChanged(ctx context.Context, e event.Event) error {
+	var msg *SyncMessage
+	switch ev := e.(type) {
+	case event.RemarkAdded:
+		msg = remarkIndexMessage(ev.RemarkSnapshot)
+	case event.RemarkEdited:
+		msg = remarkIndexMessage(ev.RemarkSnapshot)
+	case event.RemarkDeleted:
+		msg = &SyncMessage{
+			PostID:   strconv.FormatInt(ev.PostID, 10),
+			RemarkID: strconv.FormatUint(uint64(ev.RemarkID), 10),
+			DocType:  DocTypeRemark,
+			Action:   "delete",
+		}
+	default:
+		return fmt.Errorf("unexpected remark event %s", e.EventName())
+	}
+	return b.enqueue(ctx, ExchangeSearch, RoutingKeySearch, msg)
+}
+
+func remarkIndexMessage(r event.RemarkSnapshot) *SyncMessage {
+	return &SyncMessage{
+		PostID:      strconv.FormatInt(r.PostID, 10),
+		RemarkID:    strconv.FormatUint(uint64(r.RemarkID), 10),
+		AuthorID:    r.AuthorID,
+		CommunityID: r.CommunityID,
+		Content:     r.Content,
+		CreatedAt:   r.CreatedAt.Format(searchTimeLayout),
+		DocType:     DocTypeRemark,
+		Action:      "index",
+	}
+}
+
+// onVoteCast 投票事件 → 投票持久化消息，票数已知时同时更新搜索索引中的票数
+func (b *OutboxBridge) onVoteCast(ctx context.Context, e event.Event) error {
+	ev := e.(event.VoteCast)
+	id := snowflake.GenID()
+	if err := b.enqueue(ctx, ExchangeVote, RoutingKeyVote, &VoteMessage{
+		MsgID:  strconv.FormatInt(id, 10),
+		PostID: strconv.FormatInt(ev.PostID, 10),
+		UserID: strconv.FormatInt(ev.UserID, 10),
+		Action: int(ev.Direction),
+		Seq:    id,
+	}); err != nil {
+		return err
+	}
+	if ev.VoteCount < 0 {
+		return nil
+	}
+	return b.enqueue(ctx, ExchangeSearch, RoutingKeySearch, &SyncMessage{
+		PostID:    strconv.FormatInt(ev.PostID, 10),
+		VoteCount: ev.VoteCount,
+		Action:    "votes",
+	})
+}
@@ -0,0 +1,382 @@
+// Package memorymq 基于 channel 的进程内消息 Broker
+//
+// 实现 domain.Publisher / domain.Subscriber，ack/nack、prefetch、重试与死信语义与 RabbitMQ 适配器一致，
+// 用于单进程开发模式和集成测试。消息只保存在内存中，进程退出即丢失
+package memorymq
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Broker 进程内 Broker
+type Broker struct {
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+
+	mu       sync.Mutex
+	bindings map[string][]string // exchange + routingKey -> 队列名
+	queues   map[string]*queue
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBroker 创建进程内 Broker
+// 消费失败时第 n 次重试等待 backoff(n)，累计失败 maxAttempts 次后进入死信
+func NewBroker(maxAttempts int, backoff func(attempt int) time.Duration) *Broker {
+	return &Broker{
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		bindings:    make(map[string][]string),
+		queues:      make(map[string]*queue),
+		closed:      make(chan struct{}),
+	}
+}
+
+func bindingKey(exchange, routingKey string) string {
+	return exchange + "\x00" + routingKey
+}
+
+// Bind 声明队列并绑定到交换机 + 路由键，可重复调用
+func (b *Broker) Bind(exchange, routingKey, queueName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[queueName]; !ok {
+		b.queues[queueName] = newQueue()
+	}
+	key := bindingKey(exchange, routingKey)
+	for _, name := range b.bindings[key] {
+		if name == queueName {
+			return
+		}
+	}
+	b.bindings[key] = append(b.bindings[key], queueName)
+}
+
+func (b *Broker) queue(name string) (*queue, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q, ok := b.queues[name]
+	return q, ok
+}
+
+// Publish 投递消息到所有绑定的队列，未绑定任何队列的消息被丢弃（与 AMQP mandatory=false 一致）
+func (b *Broker) Publish(ctx context.Context, exchange, routingKey string, body []byte) (domain.Confirmation, error) {
+	select {
+	case <-b.closed:
+		return nil, domain.ErrBrokerDisconnected
+	default:
+	}
+
+	b.mu.Lock()
+	targets := make([]*queue, 0, len(b.bindings[bindingKey(exchange, routingKey)]))
+	for _, name := range b.bindings[bindingKey(exchange, routingKey)] {
+		targets = append(targets, b.queues[name])
+	}
+	b.mu.Unlock()
+
+	// 与 RabbitMQ 实现一致，把发布方的 trace context 和请求 ID 放进消息头
+	headers := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		headers[domain.HeaderRequestID] = id
+	}
+
+	for _, q := range targets {
+		q.push(&message{body: append([]byte(nil), body...), headers: headers, publishedAt: time.Now()})
+	}
+	return confirmed{}, nil
+}
+
+// Subscribe 订阅队列，返回的通道在 ctx 取消或 Broker 关闭后关闭
+// 订阅结束时尚未确认的消息重新入队
+func (b *Broker) Subscribe(ctx context.Context, queueName string, prefetch int) (<-chan domain.Delivery, error) {
+	q, ok := b.queue(queueName)
+	if !ok {
+		return nil, fmt.Errorf("未声明的队列: %s", queueName)
+	}
+	if prefetch < 1 {
+		prefetch = 1
+	}
+
+	sub := &subscription{
+		broker:   b,
+		q:        q,
+		prefetch: prefetch,
+		unacked:  make(map[uint64]*message),
+		freed:    make(chan struct{}, 1),
+	}
+	out := make(chan domain.Delivery)
+	go sub.run(ctx, out)
+	return out, nil
+}
+
+// DeadLetters 返回队列中已进入死信的消息体，供测试和排查使用
+func (b *Broker) DeadLetters(queueName string) [][]byte {
+	q, ok := b.queue(queueName)
+	if !ok {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	bodies := make([][]byte, 0, len(q.dead))
+	for _, m := range q.dead {
+		bodies = append(bodies, m.body)
+	}
+	return bodies
+}
+
+// Close 关闭 Broker，所有订阅随之结束
+func (b *Broker) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	return nil
+}
+
+// confirmed 进程内投递同步完成，确认句柄总是成功
+type confirmed struct{}
+
+func (confirmed) Wait(context.Context) error { return nil }
+
+// ==================== 队列 ====================
+
+type message struct {
+	body        []byte
+	headers     map[string]string
+	attempts    int
+	publishedAt time.Time
+}
+
+type queue struct {
+	mu     sync.Mutex
+	ready  []*message
+	dead   []*message
+	notify chan struct{} // 有新消息可取时发出信号
+}
+
+func newQueue() *queue {
+	return &queue{notify: make(chan struct{}, 1)}
+}
+
+func (q *queue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) push(m *message) {
+	q.mu.Lock()
+	q.ready = append(q.ready, m)
+	q.mu.Unlock()
+	q.signal()
+}
+
+// pushFront 重新入队的消息放回队头，保持原有顺序
+func (q *queue) pushFront(msgs ...*message) {
+	if len(msgs) == 0 {
+		return
+	}
+	q.mu.Lock()
+	q.ready = append(append([]*message(nil), msgs...), q.ready...)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *queue) pop() *message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ready) == 0 {
+		return nil
+	}
+	m := q.ready[0]
+	q.ready = q.ready[1:]
+	// 还有剩余消息时继续唤醒，让同一队列的其他订阅者也能取到
+	if len(q.ready) > 0 {
+		q.signal()
+	}
+	return m
+}
+
+func (q *queue) deadLetter(m *message) {
+	q.mu.Lock()
+	q.dead = append(q.dead, m)
+	q.mu.Unlock()
+}
+
+// ==================== 订阅 ====================
+
+type subscription struct {
+	broker   *Broker
+	q        *queue
+	prefetch int
+
+	mu      sync.Mutex
+	nextTag uint64
+	unacked map[uint64]*message
+	freed   chan struct{} // 确认后腾出 prefetch 名额时发出信号
+}
+
+func (s *subscription) run(ctx context.Context, out chan<- domain.Delivery) {
+	defer close(out)
+	defer s.requeueUnacked()
+
+	for {
+		// 1. 未确认消息达到 prefetch 上限时等待确认
+		for s.inflight() >= s.prefetch {
+			select {
+			case <-s.freed:
+			case <-ctx.Done():
+				return
+			case <-s.broker.closed:
+				return
+			}
+		}
+
+		// 2. 取消息，队列为空时等待投递
+		m := s.q.pop()
+		if m == nil {
+			select {
+			case <-s.q.notify:
+				continue
+			case <-ctx.Done():
+				return
+			case <-s.broker.closed:
+				return
+			}
+		}
+
+		tag := s.track(m)
+		select {
+		case out <- &delivery{sub: s, tag: tag, msg: m}:
+		case <-ctx.Done():
+			return
+		case <-s.broker.closed:
+			return
+		}
+	}
+}
+
+func (s *subscription) inflight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.unacked)
+}
+
+func (s *subscription) track(m *message) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextTag++
+	s.unacked[s.nextTag] = m
+	return s.nextTag
+}
+
+// settle 移除 tag（multiple 时包括此前所有 tag）对应的未确认消息，按投递顺序返回
+func (s *subscription) settle(tag uint64, multiple bool) ([]*message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.unacked[tag]; !ok {
+		return nil, fmt.Errorf("unknown delivery tag %d", tag)
+	}
+	var msgs []*message
+	if multiple {
+		msgs = s.takeUnacked(func(t uint64) bool { return t <= tag })
+	} else {
+		msgs = append(msgs, s.unacked[tag])
+		delete(s.unacked, tag)
+	}
+
+	select {
+	case s.freed <- struct{}{}:
+	default:
+	}
+	return msgs, nil
+}
+
+// takeUnacked 移除满足条件的未确认消息并按投递顺序返回，调用方需持有 s.mu
+func (s *subscription) takeUnacked(match func(tag uint64) bool) []*message {
+	tags := make([]uint64, 0, len(s.unacked))
+	for t := range s.unacked {
+		if match(t) {
+			tags = append(tags, t)
+		}
+	}
+	slices.Sort(tags)
+
+	msgs := make([]*message, 0, len(tags))
+	for _, t := range tags {
+		msgs = append(msgs, s.unacked[t])
+		delete(s.unacked, t)
+	}
+	return msgs
+}
+
+// requeueUnacked 订阅结束时把未确认消息放回队列，与 AMQP 信道关闭的行为一致
+func (s *subscription) requeueUnacked() {
+	s.mu.Lock()
+	msgs := s.takeUnacked(func(uint64) bool { return true })
+	s.mu.Unlock()
+
+	s.q.pushFront(msgs...)
+}
+
+// delivery 实现 domain.Delivery
+type delivery struct {
+	sub *subscription
+	tag uint64
+	msg *message
+}
+
+func (d *delivery) Body() []byte { return d.msg.body }
+
+func (d *delivery) Headers() map[string]string { return d.msg.headers }
+
+// PublishedAt 消息发布时间，用于统计消费延迟
+func (d *delivery) PublishedAt() time.Time { return d.msg.publishedAt }
+
+func (d *delivery) Ack(multiple bool) error {
+	_, err := d.sub.settle(d.tag, multiple)
+	return err
+}
+
+func (d *delivery) Nack(multiple, requeue bool) error {
+	msgs, err := d.sub.settle(d.tag, multiple)
+	if err != nil {
+		return err
+	}
+	if requeue {
+		d.sub.q.pushFront(msgs...)
+	}
+	return nil
+}
+
+func (d *delivery) Retry(_ context.Context, cause error) error {
+	if _, err := d.sub.settle(d.tag, false); err != nil {
+		return err
+	}
+
+	m := d.msg
+	m.attempts++
+	if domain.IsPermanent(cause) || m.attempts >= d.sub.broker.maxAttempts {
+		d.sub.q.deadLetter(m)
+		return nil
+	}
+	q := d.sub.q
+	time.AfterFunc(d.sub.broker.backoff(m.attempts), func() {
+		q.push(m)
+	})
+	return nil
+}
@@ -0,0 +1,128 @@
+package memorymq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+)
+
+func newTestBroker(maxAttempts int) *Broker {
+	b := NewBroker(maxAttempts, func(int) time.Duration { return time.Millisecond })
+	b.Bind("ex", "rk", "q")
+	return b
+}
+
+func receive(t *testing.T, ch <-chan domain.Delivery) domain.Delivery {
+	t.Helper()
+	select {
+	case d, ok := <-ch:
+		if !ok {
+			t.Fatal("delivery channel closed")
+		}
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+	return nil
+}
+
+func TestBroker_PublishAck(t *testing.T) {
+	b := newTestBroker(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliveries, err := b.Subscribe(ctx, "q", 1)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, err := b.Subscribe(ctx, "missing", 1); err == nil {
+		t.Error("Subscribe() on undeclared queue should fail")
+	}
+
+	confirm, err := b.Publish(ctx, "ex", "rk", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := confirm.Wait(ctx); err != nil {
+		t.Errorf("Confirmation.Wait() error = %v", err)
+	}
+
+	d := receive(t, deliveries)
+	if string(d.Body()) != "hello" {
+		t.Errorf("Body() = %q, want %q", d.Body(), "hello")
+	}
+	if err := d.Ack(false); err != nil {
+		t.Errorf("Ack() error = %v", err)
+	}
+	if err := d.Ack(false); err == nil {
+		t.Error("second Ack() should fail with unknown delivery tag")
+	}
+}
+
+func TestBroker_RetryThenDeadLetter(t *testing.T) {
+	b := newTestBroker(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliveries, _ := b.Subscribe(ctx, "q", 1)
+	_, _ = b.Publish(ctx, "ex", "rk", []byte("boom"))
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		d := receive(t, deliveries)
+		if err := d.Retry(ctx, errors.New("transient")); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+	}
+
+	if dead := b.DeadLetters("q"); len(dead) != 1 || string(dead[0]) != "boom" {
+		t.Errorf("DeadLetters() = %q, want [boom]", dead)
+	}
+
+	// 永久错误不重试，直接进入死信
+	_, _ = b.Publish(ctx, "ex", "rk", []byte("bad"))
+	d := receive(t, deliveries)
+	_ = d.Retry(ctx, domain.Permanent(errors.New("malformed")))
+	if dead := b.DeadLetters("q"); len(dead) != 2 {
+		t.Errorf("len(DeadLetters()) = %d, want 2", len(dead))
+	}
+}
+
+func TestBroker_PrefetchAndMultipleAck(t *testing.T) {
+	b := newTestBroker(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliveries, _ := b.Subscribe(ctx, "q", 2)
+	for _, body := range []string{"1", "2", "3"} {
+		_, _ = b.Publish(ctx, "ex", "rk", []byte(body))
+	}
+
+	receive(t, deliveries)
+	second := receive(t, deliveries)
+
+	// 已达 prefetch 上限，第三条消息在确认前不应投递
+	select {
+	case d := <-deliveries:
+		t.Fatalf("unexpected delivery %q beyond prefetch", d.Body())
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := second.Ack(true); err != nil {
+		t.Fatalf("Ack(multiple) error = %v", err)
+	}
+	third := receive(t, deliveries)
+	if string(third.Body()) != "3" {
+		t.Errorf("Body() = %q, want %q", third.Body(), "3")
+	}
+
+	// Nack 重新入队后再次投递
+	if err := third.Nack(false, true); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+	if again := receive(t, deliveries); string(again.Body()) != "3" {
+		t.Errorf("requeued Body() = %q, want %q", again.Body(), "3")
+	}
+}
@@ -7,6 +7,8 @@ type VoteMessage struct {
 	PostID string `json:"post_id"`
 	UserID string `json:"user_id"`
 	Action int    `json:"action"` // 1=upvote, -1=downvote
+	// Seq 投票序号（Snowflake ID，随投票时间递增），消费端据此丢弃乱序到达的旧投票；旧消息没有该字段时取 MsgID
+	Seq int64 `json:"seq,omitempty"`
 }
 
 // SyncMessage ES 搜索同步消息
@@ -19,5 +21,12 @@ type SyncMessage struct {
 	Content     string `json:"content"`
 	Status      int8   `json:"status"` // post status: 1=published
 	CreatedAt   string `json:"created_at"`
-	Action      string `json:"action"` // "index" or "delete"
+	VoteCount   int64  `json:"vote_count,omitempty"`
+	Action      string `json:"action"` // "index", "delete", "purge" (删除帖子及其评论) or "votes"
+	// DocType 文档类型：空或 "post" 为帖子，"remark" 为评论（此时 PostID 为所属帖子）
+	DocType  string `json:"doc_type,omitempty"`
+	RemarkID string `json:"remark_id,omitempty"`
 }
+
+// DocTypeRemark 评论文档的 SyncMessage.DocType
+const DocTypeRemark = "remark"
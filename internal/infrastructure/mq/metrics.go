@@ -0,0 +1,92 @@
+package mq
+
+import (
+	"context"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/metrics"
+)
+
+// InstrumentPublisher 为发布者加上发布结果计数，与具体 Broker 无关
+func InstrumentPublisher(p domain.Publisher) domain.Publisher {
+	return &instrumentedPublisher{next: p}
+}
+
+type instrumentedPublisher struct {
+	next domain.Publisher
+}
+
+func (p *instrumentedPublisher) Publish(ctx context.Context, exchange, routingKey string, body []byte) (domain.Confirmation, error) {
+	confirm, err := p.next.Publish(ctx, exchange, routingKey, body)
+	if err != nil {
+		metrics.MQPublished.WithLabelValues(exchange, "error").Inc()
+		return nil, err
+	}
+	return &instrumentedConfirmation{next: confirm, exchange: exchange}, nil
+}
+
+// instrumentedConfirmation 在等待确认时记录结果；调用方不等待确认的消息不计入
+type instrumentedConfirmation struct {
+	next     domain.Confirmation
+	exchange string
+}
+
+func (c *instrumentedConfirmation) Wait(ctx context.Context) error {
+	if err := c.next.Wait(ctx); err != nil {
+		metrics.MQPublished.WithLabelValues(c.exchange, "nack").Inc()
+		return err
+	}
+	metrics.MQPublished.WithLabelValues(c.exchange, "ok").Inc()
+	return nil
+}
+
+// InstrumentSubscriber 为订阅者加上消费计数、失败计数和消费延迟
+// 消费延迟依赖 Delivery 提供 PublishedAt()，RabbitMQ 和进程内实现均已提供
+func InstrumentSubscriber(s domain.Subscriber) domain.Subscriber {
+	return &instrumentedSubscriber{next: s}
+}
+
+type instrumentedSubscriber struct {
+	next domain.Subscriber
+}
+
+func (s *instrumentedSubscriber) Subscribe(ctx context.Context, queue string, prefetch int) (<-chan domain.Delivery, error) {
+	in, err := s.next.Subscribe(ctx, queue, prefetch)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan domain.Delivery)
+	go func() {
+		defer close(out)
+		for d := range in {
+			metrics.MQConsumed.WithLabelValues(queue).Inc()
+			if p, ok := d.(interface{ PublishedAt() time.Time }); ok && !p.PublishedAt().IsZero() {
+				metrics.MQConsumeLag.WithLabelValues(queue).Observe(time.Since(p.PublishedAt()).Seconds())
+			}
+			// ctx 取消后消费者可能不再读取；此时放弃转发，未确认的消息由 Broker 在订阅结束时重新入队
+			select {
+			case out <- &instrumentedDelivery{Delivery: d, queue: queue}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+type instrumentedDelivery struct {
+	domain.Delivery
+	queue string
+}
+
+func (d *instrumentedDelivery) Nack(multiple, requeue bool) error {
+	metrics.MQConsumeFailures.WithLabelValues(d.queue, "nack").Inc()
+	return d.Delivery.Nack(multiple, requeue)
+}
+
+func (d *instrumentedDelivery) Retry(ctx context.Context, cause error) error {
+	metrics.MQConsumeFailures.WithLabelValues(d.queue, "retry").Inc()
+	return d.Delivery.Retry(ctx, cause)
+}
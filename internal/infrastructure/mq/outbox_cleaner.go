@@ -0,0 +1,90 @@
+package mq
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain"
+)
+
+// outboxCleanupBatch 单条 DELETE 最多删除的行数
+const outboxCleanupBatch = 1000
+
+// OutboxCleaner 发件箱清理任务
+// 已投递的消息只用于排查问题，超过保留期后定期删除，避免 outbox 表无限增长；
+// 失败的消息需要人工介入，不会被清理。多实例同时运行时各自删除，互不影响
+type OutboxCleaner struct {
+	outboxRepo domain.OutboxRepository
+	retention  time.Duration
+	interval   time.Duration
+
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewOutboxCleaner 创建发件箱清理任务，删除 retention 之前投递成功的消息
+func NewOutboxCleaner(outboxRepo domain.OutboxRepository, retention, interval time.Duration) *OutboxCleaner {
+	return &OutboxCleaner{
+		outboxRepo: outboxRepo,
+		retention:  retention,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start 启动后台清理任务，启动时立即执行一次
+func (c *OutboxCleaner) Start() {
+	log.Printf("[OutboxCleaner] started, retention: %v, interval: %v", c.retention, c.interval)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			n, err := c.cleanup(time.Now().Add(-c.retention))
+			if err != nil {
+				log.Printf("[OutboxCleaner] cleanup error: %v", err)
+			} else if n > 0 {
+				log.Printf("[OutboxCleaner] deleted %d sent messages", n)
+			}
+
+			select {
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// cleanup 分批删除 before 之前投递成功的消息，返回删除的总数
+func (c *OutboxCleaner) cleanup(before time.Time) (int64, error) {
+	var total int64
+	for {
+		select {
+		case <-c.stopCh:
+			return total, nil
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		n, err := c.outboxRepo.DeleteSent(ctx, before, outboxCleanupBatch)
+		cancel()
+		total += n
+		if err != nil || n < outboxCleanupBatch {
+			return total, err
+		}
+	}
+}
+
+// Stop 停止清理任务，等待正在执行的批次完成
+func (c *OutboxCleaner) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+	c.wg.Wait()
+}
@@ -0,0 +1,34 @@
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// backlogOutbox 模拟积压了 remaining 条过期消息的发件箱
+type backlogOutbox struct {
+	fakeOutbox
+	remaining int64
+	calls     int
+}
+
+func (o *backlogOutbox) DeleteSent(_ context.Context, _ time.Time, limit int) (int64, error) {
+	o.calls++
+	n := min(o.remaining, int64(limit))
+	o.remaining -= n
+	return n, nil
+}
+
+func TestOutboxCleaner_DeletesInBatches(t *testing.T) {
+	outbox := &backlogOutbox{remaining: 2*outboxCleanupBatch + 1}
+	c := NewOutboxCleaner(outbox, 24*time.Hour, time.Hour)
+
+	n, err := c.cleanup(time.Now())
+	if err != nil {
+		t.Fatalf("cleanup() error = %v", err)
+	}
+	if n != 2*outboxCleanupBatch+1 || outbox.calls != 3 {
+		t.Errorf("cleanup() deleted %d in %d calls, want %d in 3", n, outbox.calls, 2*outboxCleanupBatch+1)
+	}
+}
@@ -0,0 +1,200 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	relayBatchTimeout = 30 * time.Second      // 单批领取、投递并等待确认的时限
+	relayMarkTimeout  = 5 * time.Second       // 投递后更新消息状态的时限
+	outboxLease       = 2 * relayBatchTimeout // 领取后的租约，须长于单批处理时限，避免投递途中被其他实例重复领取
+)
+
+// OutboxRelay 发件箱中继
+// 周期性地从 outbox 表领取待投递消息，以持久化 + Publisher Confirms 的方式投递到 Broker，
+// Broker 确认后再标记为已投递。多实例并行时依靠 SKIP LOCKED 和租约分摊批次。
+// 语义为至少一次投递，消费者需自行保证幂等
+type OutboxRelay struct {
+	publisher   domain.Publisher
+	outboxRepo  domain.OutboxRepository
+	txManager   domain.TransactionManager
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewOutboxRelay 创建发件箱中继，RabbitMQ 下 publisher 须由 NewConfirmPublisher 创建
+func NewOutboxRelay(
+	publisher domain.Publisher,
+	outboxRepo domain.OutboxRepository,
+	txManager domain.TransactionManager,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+) *OutboxRelay {
+	return &OutboxRelay{
+		publisher:   publisher,
+		outboxRepo:  outboxRepo,
+		txManager:   txManager,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start 启动后台中继任务
+func (r *OutboxRelay) Start() {
+	log.Printf("[OutboxRelay] started, interval: %v, batch size: %d, max attempts: %d",
+		r.interval, r.batchSize, r.maxAttempts)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			// 一批全部投递成功说明可能还有积压，立即继续下一批；否则等待下一次触发
+			for {
+				select {
+				case <-r.stopCh:
+					return
+				default:
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), relayBatchTimeout)
+				n, err := r.relayBatch(ctx)
+				cancel()
+				if err != nil {
+					log.Printf("[OutboxRelay] relay batch error: %v", err)
+					break
+				}
+				if n < r.batchSize {
+					break
+				}
+			}
+
+			select {
+			case <-r.stopCh:
+				log.Println("[OutboxRelay] receiving stop signal, exiting loop...")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// relayBatch 投递一批消息，返回本批投递成功的消息数
+//
+// 领取、投递、标记分为三步：领取在短事务中完成（锁定后写入租约即提交），投递和等待确认不持有任何事务，
+// 最后逐条更新状态。Broker 缓慢或重连期间不会长时间占用数据库连接和行锁；
+// 中继在投递途中崩溃时，租约到期后消息被重新领取投递
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	// 1. 领取一批消息
+	var msgs []*entity.OutboxMessage
+	err := r.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		msgs, err = r.outboxRepo.FetchPending(ctx, r.batchSize)
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+		ids := make([]int64, 0, len(msgs))
+		for _, m := range msgs {
+			ids = append(ids, m.ID)
+		}
+		return r.outboxRepo.Lease(ctx, ids, time.Now().Add(outboxLease))
+	})
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+
+	// 2. 先全部发出，再统一等待确认，避免逐条同步等待
+	//    每条消息在写入发件箱时的链路下创建 Producer Span，消费者据此接上同一条链路
+	type pending struct {
+		msg     *entity.OutboxMessage
+		confirm domain.Confirmation
+		span    trace.Span
+	}
+	inflight := make([]pending, 0, len(msgs))
+	var unsent []int64 // 因断线未能投递的消息，释放租约等待重连
+	for i, m := range msgs {
+		pubCtx, span := startPublishSpan(outboxContext(ctx, m.Headers), m.Exchange, m.RoutingKey)
+		confirm, err := r.publisher.Publish(pubCtx, m.Exchange, m.RoutingKey, m.Payload)
+		if errors.Is(err, domain.ErrBrokerDisconnected) {
+			// 断线：剩余消息保持待投递状态等待重连，不计入失败次数
+			endSpan(span, err)
+			for _, rest := range msgs[i:] {
+				unsent = append(unsent, rest.ID)
+			}
+			break
+		}
+		if err != nil {
+			endSpan(span, err)
+			r.markFailed(ctx, m, err)
+			continue
+		}
+		inflight = append(inflight, pending{msg: m, confirm: confirm, span: span})
+	}
+
+	// 3. 收集 Broker 确认结果
+	sentIDs := make([]int64, 0, len(inflight))
+	for _, p := range inflight {
+		err := p.confirm.Wait(ctx)
+		endSpan(p.span, err)
+		if errors.Is(err, domain.ErrBrokerDisconnected) {
+			// 等待确认期间断线：是否送达未知，保持待投递状态，重连后重新投递
+			unsent = append(unsent, p.msg.ID)
+			continue
+		}
+		if err != nil {
+			r.markFailed(ctx, p.msg, err)
+			continue
+		}
+		sentIDs = append(sentIDs, p.msg.ID)
+	}
+
+	// 4. 更新状态；等待确认可能已耗尽 ctx 的时限，标记使用独立的超时
+	markCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), relayMarkTimeout)
+	defer cancel()
+	if err := r.outboxRepo.Release(markCtx, unsent); err != nil {
+		// 释放失败不影响正确性，租约到期后照常重新领取
+		log.Printf("[OutboxRelay] release outbox messages failed: %v", err)
+	}
+	if err := r.outboxRepo.MarkSent(markCtx, sentIDs); err != nil {
+		return 0, err
+	}
+	return len(sentIDs), nil
+}
+
+// markFailed 记录投递失败，错误不向上传播，不影响同批其他消息的标记
+func (r *OutboxRelay) markFailed(ctx context.Context, m *entity.OutboxMessage, cause error) {
+	log.Printf("[OutboxRelay] publish outbox message failed (id: %d, attempts: %d): %v", m.ID, m.Attempts+1, cause)
+	markCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), relayMarkTimeout)
+	defer cancel()
+	if err := r.outboxRepo.MarkFailed(markCtx, m.ID, cause.Error(), r.maxAttempts); err != nil {
+		log.Printf("[OutboxRelay] mark outbox message failed error (id: %d): %v", m.ID, err)
+	}
+}
+
+// Stop 停止中继任务，等待正在处理的批次完成
+func (r *OutboxRelay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopCh)
+	})
+
+	r.wg.Wait()
+	log.Println("[OutboxRelay] stopped completely")
+}
@@ -0,0 +1,114 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+)
+
+type txKey struct{}
+
+// fakeTx 在 ctx 中标记事务，供断言投递不在事务内进行
+type fakeTx struct{}
+
+func (fakeTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
+
+func inTx(ctx context.Context) bool { return ctx.Value(txKey{}) != nil }
+
+// fakeOutbox 记录领取和标记结果的发件箱
+type fakeOutbox struct {
+	domain.OutboxRepository
+	pending  []*entity.OutboxMessage
+	leased   []int64
+	released []int64
+	sent     []int64
+	failed   []int64
+}
+
+func (o *fakeOutbox) Lease(ctx context.Context, ids []int64, _ time.Time) error {
+	if !inTx(ctx) {
+		return errors.New("Lease() outside the claim transaction")
+	}
+	o.leased = append(o.leased, ids...)
+	return nil
+}
+
+func (o *fakeOutbox) Release(_ context.Context, ids []int64) error {
+	o.released = append(o.released, ids...)
+	return nil
+}
+
+func (o *fakeOutbox) FetchPending(context.Context, int) ([]*entity.OutboxMessage, error) {
+	return o.pending, nil
+}
+
+func (o *fakeOutbox) MarkSent(_ context.Context, ids []int64) error {
+	o.sent = append(o.sent, ids...)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(_ context.Context, id int64, _ string, _ int) error {
+	o.failed = append(o.failed, id)
+	return nil
+}
+
+type confirmFunc func(ctx context.Context) error
+
+func (f confirmFunc) Wait(ctx context.Context) error { return f(ctx) }
+
+// scriptedPublisher 按负载返回预设的发布错误和确认结果
+type scriptedPublisher struct {
+	publishErr map[string]error
+	confirmErr map[string]error
+}
+
+func (p *scriptedPublisher) Publish(ctx context.Context, _, _ string, body []byte) (domain.Confirmation, error) {
+	if inTx(ctx) {
+		return nil, errors.New("Publish() inside a database transaction")
+	}
+	if err := p.publishErr[string(body)]; err != nil {
+		return nil, err
+	}
+	err := p.confirmErr[string(body)]
+	return confirmFunc(func(context.Context) error { return err }), nil
+}
+
+func TestOutboxRelay_DisconnectLeavesMessagesPending(t *testing.T) {
+	outbox := &fakeOutbox{pending: []*entity.OutboxMessage{
+		{ID: 1, Payload: []byte("ok")},
+		{ID: 2, Payload: []byte("nack")},
+		{ID: 3, Payload: []byte("lost")},
+		{ID: 4, Payload: []byte("closed")},
+		{ID: 5, Payload: []byte("after")},
+	}}
+	pub := &scriptedPublisher{
+		publishErr: map[string]error{"closed": ErrNotConnected},
+		confirmErr: map[string]error{
+			"nack": errors.New("broker nack"),
+			"lost": ErrNotConnected,
+		},
+	}
+	relay := NewOutboxRelay(pub, outbox, fakeTx{}, 0, 10, 3)
+
+	n, err := relay.relayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("relayBatch() error = %v", err)
+	}
+	// 确认前断线的消息和断线后未发出的消息都保持待投递，只有被 Broker 拒收的计入失败
+	if n != 1 || !reflect.DeepEqual(outbox.sent, []int64{1}) {
+		t.Errorf("sent = %v (n = %d), want [1]", outbox.sent, n)
+	}
+	if !reflect.DeepEqual(outbox.failed, []int64{2}) {
+		t.Errorf("failed = %v, want [2]", outbox.failed)
+	}
+	if !reflect.DeepEqual(outbox.leased, []int64{1, 2, 3, 4, 5}) || !reflect.DeepEqual(outbox.released, []int64{4, 5, 3}) {
+		t.Errorf("leased = %v, released = %v; want all leased, 3-5 released", outbox.leased, outbox.released)
+	}
+}
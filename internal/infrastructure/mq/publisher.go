@@ -2,52 +2,167 @@ package mq
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"bluebell/internal/domain"
+
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 // Publisher 生产者：只认信道，不认连接
+// 信道从连接管理器按需打开，断线后下一次发送时重新打开；断线期间发送快速失败并返回 ErrNotConnected，
+// 由发件箱（OutboxRelay）在断线期间充当缓冲
 type Publisher struct {
-	ch *amqp.Channel
+	conn *ConnectionManager
+
+	mu      sync.Mutex
+	ch      *amqp.Channel
+	returns chan amqp.Return // 当前信道上被 Broker 退回的 mandatory 消息
+
+	sendMu sync.Mutex // 串行化 PublishAndWait
 }
 
-// NewPublisher 创建一个新的生产者
-func NewPublisher(ch *amqp.Channel) *Publisher {
-	return &Publisher{ch: ch}
+// ErrUnroutable mandatory 消息无法路由到任何队列，被 Broker 退回
+var ErrUnroutable = errors.New("rabbitmq: message returned as unroutable")
+
+// NewConfirmPublisher 创建一个开启 Publisher Confirms 的生产者
+// 信道进入 confirm 模式后，每条消息都会收到 Broker 的 ack/nack；信道在首次发布时打开，创建时不要求已连接
+func NewConfirmPublisher(conn *ConnectionManager) *Publisher {
+	return &Publisher{conn: conn}
 }
 
-// Send 通用的发送方法
-func (p *Publisher) Send(ctx context.Context, exchange, routingKey string, msg interface{}) error {
-	body, err := json.Marshal(msg)
+// channel 返回当前可用的信道，信道已失效时重新打开
+func (p *Publisher) channel() (*amqp.Channel, error) {
+	ch, _, err := p.channelWithReturns()
+	return ch, err
+}
+
+// channelWithReturns 返回当前可用的信道及其退回消息通道
+func (p *Publisher) channelWithReturns() (*amqp.Channel, chan amqp.Return, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, p.returns, nil
+	}
+	ch, err := p.conn.Channel()
 	if err != nil {
-		return fmt.Errorf("序列化失败: %w", err)
+		return nil, nil, err
 	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, nil, fmt.Errorf("开启 Publisher Confirms 失败: %w", err)
+	}
+	p.ch = ch
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+	return ch, p.returns, nil
+}
 
-	// 注入 Trace 上下文到 Headers
+// Close 关闭生产者信道
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ch == nil || p.ch.IsClosed() {
+		return nil
+	}
+	return p.ch.Close()
+}
 
-	return p.ch.PublishWithContext(ctx,
+// Publish 以持久化方式发送已序列化的消息，返回 Broker 确认的句柄，实现 domain.Publisher
+// 断线期间快速失败并返回 ErrNotConnected
+func (p *Publisher) Publish(ctx context.Context, exchange, routingKey string, body []byte) (domain.Confirmation, error) {
+	ch, err := p.channel()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := ch.PublishWithDeferredConfirmWithContext(ctx,
 		exchange,
 		routingKey,
 		false,
 		false,
 		amqp.Publishing{
-			DeliveryMode: amqp.Transient,
+			Headers:      traceHeaders(ctx),
+			DeliveryMode: amqp.Persistent,
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
 		},
 	)
+	if err != nil {
+		return nil, brokerError(ch, err)
+	}
+	if dc == nil {
+		return nil, fmt.Errorf("publisher is not in confirm mode")
+	}
+	return deferredConfirmation{dc: dc, ch: ch}, nil
 }
 
-// PublishVote 发布投票消息
-func (p *Publisher) PublishVote(ctx context.Context, msg *VoteMessage) error {
-	return p.Send(ctx, ExchangeVote, RoutingKeyVote, msg)
+// brokerError 把信道或连接关闭导致的错误映射为 ErrNotConnected，调用方据此等待重连而不是记为投递失败
+func brokerError(ch *amqp.Channel, err error) error {
+	var amqpErr *amqp.Error
+	closed := errors.Is(err, amqp.ErrClosed) || ch.IsClosed() ||
+		errors.As(err, &amqpErr) && (amqpErr.Code == amqp.ChannelError || amqpErr.Code == amqp.ConnectionForced)
+	if closed {
+		return fmt.Errorf("%w: %v", ErrNotConnected, err)
+	}
+	return err
+}
+
+// deferredConfirmation 将 AMQP 的 DeferredConfirmation 适配为 domain.Confirmation
+type deferredConfirmation struct {
+	dc *amqp.DeferredConfirmation
+	ch *amqp.Channel
+}
+
+func (c deferredConfirmation) Wait(ctx context.Context) error {
+	acked, err := c.dc.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		// 信道关闭时未确认的消息会被统一 nack，这种情况视为断线而不是 Broker 拒收
+		if c.ch.IsClosed() {
+			return fmt.Errorf("%w: channel closed before confirm", ErrNotConnected)
+		}
+		return fmt.Errorf("broker nack")
+	}
+	return nil
 }
 
-// PublishSearch 发布搜索同步消息
-func (p *Publisher) PublishSearch(ctx context.Context, msg interface{}) error {
-	return p.Send(ctx, ExchangeSearch, RoutingKeySearch, msg)
+// PublishAndWait 以 mandatory 方式投递 msg 并同步等待 Broker 确认，被退回、nack 或断线时返回错误
+// 调用在同一 Publisher 上串行执行：Broker 对无法路由的消息先发 basic.return 再发 ack，
+// 串行后确认前收到的退回必然属于本条消息。适用于重试、死信等低频且不能丢失的投递
+func (p *Publisher) PublishAndWait(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+
+	ch, returns, err := p.channelWithReturns()
+	if err != nil {
+		return err
+	}
+	// 丢弃此前等待超时的消息迟到的退回
+	for drained := false; !drained; {
+		select {
+		case <-returns:
+		default:
+			drained = true
+		}
+	}
+
+	dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, true, false, msg)
+	if err != nil {
+		return brokerError(ch, err)
+	}
+	if err := (deferredConfirmation{dc: dc, ch: ch}).Wait(ctx); err != nil {
+		return err
+	}
+	select {
+	case r := <-returns:
+		return fmt.Errorf("%w: %s (exchange: %q, routing key: %q)", ErrUnroutable, r.ReplyText, r.Exchange, r.RoutingKey)
+	default:
+		return nil
+	}
 }
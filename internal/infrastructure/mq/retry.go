@@ -0,0 +1,131 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bluebell/internal/config"
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/logger"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// 重试相关消息头
+const (
+	HeaderRetryCount  = "x-retry-count"  // 已失败次数
+	HeaderLastError   = "x-last-error"   // 最近一次失败原因
+	HeaderOriginQueue = "x-origin-queue" // 消息原本所在的业务队列
+	HeaderDeadAt      = "x-dead-at"      // 进入死信队列的时间（RFC3339）
+)
+
+// RetryPolicy 消费失败重试策略
+// 第 n 次失败后等待 BaseDelay * 2^(n-1)（不超过 MaxDelay）再重新投递，
+// 累计失败 MaxAttempts 次后进入死信队列
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// RetryPolicyFromConfig 从配置构建重试策略，取值范围已由配置校验保证
+func RetryPolicyFromConfig(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: cfg.RabbitMQ.MaxAttempts,
+		BaseDelay:   cfg.RabbitMQ.RetryBaseDelay,
+		MaxDelay:    cfg.RabbitMQ.RetryMaxDelay,
+	}
+}
+
+// Delay 返回第 attempt 次失败后的退避时长（attempt 从 1 开始）
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Delays 返回所有可能用到的退避时长（已去重），用于声明重试队列
+func (p RetryPolicy) Delays() []time.Duration {
+	var delays []time.Duration
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		d := p.Delay(attempt)
+		if len(delays) == 0 || delays[len(delays)-1] != d {
+			delays = append(delays, d)
+		}
+	}
+	return delays
+}
+
+// retryCount 读取消息已失败的次数
+func retryCount(d amqp.Delivery) int {
+	switch v := d.Headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// failureHandler 消费失败处理器
+// 根据重试次数把消息投递到对应延迟的重试队列或死信交换机，Broker 确认且消息成功路由后才 ack 原消息；
+// 投递失败、被 nack 或被退回（mandatory）时 nack 并重新入队，保证消息不会丢失
+type failureHandler struct {
+	topology  Topology
+	policy    RetryPolicy
+	publisher *Publisher // confirm 模式的专用发布者，与消费信道分离
+}
+
+func (h *failureHandler) handle(ctx context.Context, d amqp.Delivery, cause error) error {
+	attempts := retryCount(d) + 1
+
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderRetryCount] = int32(attempts)
+	headers[HeaderLastError] = cause.Error()
+	headers[HeaderOriginQueue] = h.topology.Queue
+
+	var exchange, routingKey string
+	if domain.IsPermanent(cause) || attempts >= h.policy.MaxAttempts {
+		exchange, routingKey = h.topology.DLX, h.topology.Queue
+		headers[HeaderDeadAt] = time.Now().Format(time.RFC3339)
+		logger.WithContext(ctx).Warn("message dead-lettered",
+			zap.String("dlq", h.topology.DLQ),
+			zap.Int("attempts", attempts),
+			zap.Error(cause))
+	} else {
+		// 默认交换机按队列名直接路由到重试队列
+		exchange, routingKey = "", h.topology.RetryQueue(h.policy.Delay(attempts))
+		logger.WithContext(ctx).Warn("message scheduled for retry",
+			zap.String("queue", h.topology.Queue),
+			zap.Duration("delay", h.policy.Delay(attempts)),
+			zap.Int("attempts", attempts),
+			zap.Error(cause))
+	}
+
+	err := h.publisher.PublishAndWait(ctx, exchange, routingKey, amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		ContentType:  d.ContentType,
+		Body:         d.Body,
+		Timestamp:    d.Timestamp,
+		MessageId:    d.MessageId,
+	})
+	if err != nil {
+		logger.WithContext(ctx).Error("publish retry/dead-letter message failed, requeued", zap.Error(err))
+		_ = d.Nack(false, true)
+		return fmt.Errorf("投递重试/死信消息失败: %w", err)
+	}
+	return d.Ack(false)
+}
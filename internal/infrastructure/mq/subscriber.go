@@ -0,0 +1,94 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bluebell/internal/domain"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Subscriber RabbitMQ 订阅者，实现 domain.Subscriber
+// 每个订阅使用独立信道，断线重连后在新信道上继续投递；消费失败按 RetryPolicy 进入重试队列或死信队列
+type Subscriber struct {
+	conn    *ConnectionManager
+	policy  RetryPolicy
+	retries *Publisher // 投递重试和死信消息，所有订阅共用
+}
+
+// NewSubscriber 创建一个新的订阅者
+func NewSubscriber(conn *ConnectionManager, policy RetryPolicy) *Subscriber {
+	return &Subscriber{conn: conn, policy: policy, retries: NewConfirmPublisher(conn)}
+}
+
+// Subscribe 订阅业务队列，返回的通道在 ctx 取消或连接管理器关闭后关闭
+func (s *Subscriber) Subscribe(ctx context.Context, queue string, prefetch int) (<-chan domain.Delivery, error) {
+	t, ok := lookupTopologyByQueue(queue)
+	if !ok {
+		return nil, fmt.Errorf("未声明的队列: %s", queue)
+	}
+	failures := &failureHandler{topology: t, policy: s.policy, publisher: s.retries}
+
+	out := make(chan domain.Delivery)
+	go func() {
+		defer close(out)
+		_ = s.conn.RunWithChannel(ctx, "订阅 "+queue, func(ctx context.Context, ch *amqp.Channel) error {
+			if err := ch.Qos(prefetch, 0, false); err != nil {
+				return fmt.Errorf("设置 QoS 失败: %w", err)
+			}
+			msgs, err := ch.Consume(queue, "", false, false, false, false, nil)
+			if err != nil {
+				return fmt.Errorf("消费队列 %s 失败: %w", queue, err)
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case d, ok := <-msgs:
+					if !ok {
+						return fmt.Errorf("消费者通道已关闭")
+					}
+					select {
+					case out <- &delivery{d: d, failures: failures}:
+					case <-ctx.Done():
+						return nil
+					}
+				}
+			}
+		})
+	}()
+	return out, nil
+}
+
+// lookupTopologyByQueue 根据业务队列名查找拓扑
+func lookupTopologyByQueue(queue string) (Topology, bool) {
+	for _, t := range Topologies() {
+		if t.Queue == queue {
+			return t, true
+		}
+	}
+	return Topology{}, false
+}
+
+// delivery 将 amqp.Delivery 适配为 domain.Delivery
+type delivery struct {
+	d        amqp.Delivery
+	failures *failureHandler
+}
+
+func (d *delivery) Body() []byte { return d.d.Body }
+
+func (d *delivery) Headers() map[string]string { return stringHeaders(d.d.Headers) }
+
+// PublishedAt 发布方写入的消息时间戳，用于统计消费延迟
+func (d *delivery) PublishedAt() time.Time { return d.d.Timestamp }
+
+func (d *delivery) Ack(multiple bool) error { return d.d.Ack(multiple) }
+
+func (d *delivery) Nack(multiple, requeue bool) error { return d.d.Nack(multiple, requeue) }
+
+func (d *delivery) Retry(ctx context.Context, cause error) error {
+	return d.failures.handle(ctx, d.d, cause)
+}
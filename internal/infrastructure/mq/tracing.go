@@ -0,0 +1,127 @@
+package mq
+
+import (
+	"context"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/logger"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// tracer 消息收发的 Tracer，Span 遵循 OpenTelemetry 消息语义约定
+var tracer = otel.Tracer("bluebell/mq")
+
+// amqpHeaderCarrier 将 amqp.Table 适配为 propagation.TextMapCarrier
+type amqpHeaderCarrier amqp.Table
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+func (c amqpHeaderCarrier) Set(key, value string) { c[key] = value }
+
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// traceHeaders 把 ctx 中的 trace context 和请求 ID 注入到新的 AMQP Header 中
+func traceHeaders(ctx context.Context) amqp.Table {
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		headers[domain.HeaderRequestID] = id
+	}
+	return headers
+}
+
+// stringHeaders 取出 AMQP Header 中的字符串项，其余类型（如重试计数）忽略
+func stringHeaders(t amqp.Table) map[string]string {
+	headers := make(map[string]string, len(t))
+	for k, v := range t {
+		if s, ok := v.(string); ok {
+			headers[k] = s
+		}
+	}
+	return headers
+}
+
+// startPublishSpan 为一次发布创建 Producer Span
+func startPublishSpan(ctx context.Context, exchange, routingKey string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, exchange+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "publish"),
+			attribute.String("messaging.destination.name", exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		),
+	)
+}
+
+// startConsumeSpan 从消息 Header 中恢复发布方的 trace context，并创建 Consumer Span
+// 返回的 ctx 同时携带请求 ID 和消息级 Logger，处理过程通过 logger.WithContext 记录日志
+func startConsumeSpan(ctx context.Context, queue string, d domain.Delivery) (context.Context, trace.Span) {
+	headers := d.Headers()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+	ctx, span := tracer.Start(ctx, queue+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", queue),
+			attribute.Int("messaging.message.body.size", len(d.Body())),
+		),
+	)
+
+	if id := headers[domain.HeaderRequestID]; id != "" {
+		ctx = logger.ContextWithRequestID(ctx, id)
+	}
+	l := zap.L().With(zap.String("queue", queue))
+	ctx = logger.NewContext(ctx, l.With(logger.ContextFields(ctx)...))
+	return ctx, span
+}
+
+// endSpan 结束 Span，err 非空时记录为失败
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// injectOutboxHeaders 在写入发件箱时保存当前的 trace context 和请求 ID，中继投递时据此延续链路
+func injectOutboxHeaders(ctx context.Context) map[string]string {
+	headers := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		headers[domain.HeaderRequestID] = id
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// outboxContext 恢复发件箱消息写入时的 trace context 和请求 ID
+func outboxContext(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	if id := headers[domain.HeaderRequestID]; id != "" {
+		ctx = logger.ContextWithRequestID(ctx, id)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
@@ -4,96 +4,235 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strconv"
 	"time"
 
 	"bluebell/internal/domain"
 	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/logger"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+// voteDedupTTL 投票消息去重 key 的有效期
+const voteDedupTTL = 24 * time.Hour
+
 // VoteConsumer 投票消息消费者
+// 一次预取最多 batchSize 条消息，按 (user, post) 合并只保留最后一次操作，
+// 攒满一批或等待 flushInterval 后以单条多行 UPSERT 落库，并用 multiple=true 批量确认
 type VoteConsumer struct {
-	ch       *amqp.Channel
-	voteRepo domain.VoteRepository
-	rdb      *redis.Client
+	sub           domain.Subscriber
+	voteRepo      domain.VoteRepository
+	rdb           *redis.Client
+	batchSize     int
+	flushInterval time.Duration
 }
 
 // NewVoteConsumer 创建一个新的投票消费者
-func NewVoteConsumer(ch *amqp.Channel, voteRepo domain.VoteRepository, rdb *redis.Client) *VoteConsumer {
-	return &VoteConsumer{ch: ch, voteRepo: voteRepo, rdb: rdb}
+func NewVoteConsumer(sub domain.Subscriber, voteRepo domain.VoteRepository, rdb *redis.Client, batchSize int, flushInterval time.Duration) *VoteConsumer {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &VoteConsumer{
+		sub:           sub,
+		voteRepo:      voteRepo,
+		rdb:           rdb,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
 }
 
-// Start 启动监听
-func (c *VoteConsumer) Start(ctx context.Context) error {
-	if err := c.ch.Qos(1, 0, false); err != nil {
-		return fmt.Errorf("设置 QoS 失败: %w", err)
-	}
+// pendingVote 已解析、等待批量落库的投票消息
+type pendingVote struct {
+	ctx      context.Context // 消息上下文，携带请求 ID 和消息级 Logger，逐条记录日志时使用
+	delivery domain.Delivery
+	msgID    string
+	vote     *entity.Vote
+	link     trace.Link // 消息处理 Span，批量落库的 Span 通过 Link 关联到每条消息
+}
 
-	msgs, err := c.ch.Consume(QueueVote, "", false, false, false, false, nil)
+// Start 启动监听，阻塞直到 ctx 取消
+// 断线重连由订阅者负责，对消费者透明；中断时未确认的消息由 Broker 重新投递
+func (c *VoteConsumer) Start(ctx context.Context) error {
+	deliveries, err := c.sub.Subscribe(ctx, QueueVote, c.batchSize)
 	if err != nil {
-		return fmt.Errorf("消费队列 %s 失败: %w", QueueVote, err)
+		return err
 	}
 
-	log.Printf("投票消费者已启动: %s", QueueVote)
+	logger.WithContext(ctx).Info("vote consumer started",
+		zap.String("queue", QueueVote),
+		zap.Int("batch_size", c.batchSize),
+		zap.Duration("flush_interval", c.flushInterval))
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
 
+	batch := make([]pendingVote, 0, c.batchSize)
 	for {
 		select {
-		case <-ctx.Done():
-			return nil
-		case d, ok := <-msgs:
+		case d, ok := <-deliveries:
 			if !ok {
-				return fmt.Errorf("消费者通道已关闭")
+				return nil
+			}
+			// 从 Headers 提取 Trace 上下文；消息只在这里解析，真正落库在批量 flush 中
+			msgCtx, span := startConsumeSpan(ctx, QueueVote, d)
+			p, err := decodeVote(d.Body())
+			endSpan(span, err)
+			if err != nil {
+				// 格式或校验错误不可重试，单独进入死信，不影响本批其他消息
+				logger.WithContext(msgCtx).Error("handle vote message failed", zap.Error(err))
+				if err := d.Retry(msgCtx, err); err != nil {
+					logger.WithContext(msgCtx).Error("retry vote message failed", zap.Error(err))
+				}
+				continue
+			}
+			p.ctx = msgCtx
+			p.delivery = d
+			p.link = trace.Link{SpanContext: span.SpanContext()}
+			batch = append(batch, p)
+			if len(batch) >= c.batchSize {
+				c.flush(ctx, batch)
+				batch = batch[:0]
 			}
-			if err := c.handleDelivery(ctx, d); err != nil {
-				log.Printf("处理投票消息失败: %v", err)
-				_ = d.Nack(false, false)
-			} else {
-				_ = d.Ack(false)
+		case <-ticker.C:
+			if len(batch) > 0 {
+				c.flush(ctx, batch)
+				batch = batch[:0]
 			}
 		}
 	}
 }
 
-func (c *VoteConsumer) handleDelivery(ctx context.Context, d amqp.Delivery) error {
-	// 从 Headers 提取 Trace 上下文
-
+// decodeVote 解析并校验投票消息，返回的错误均为不可重试错误
+func decodeVote(body []byte) (pendingVote, error) {
 	var msg VoteMessage
-	if err := json.Unmarshal(d.Body, &msg); err != nil {
-		return fmt.Errorf("vote_consumer: 反序列化投票消息失败: %w", err)
-	}
-
-	// 幂等检查
-	dedupKey := fmt.Sprintf("bluebell:mq:dedup:vote:%s", msg.MsgID)
-	ok, err := c.rdb.SetNX(ctx, dedupKey, "1", 24*time.Hour).Result()
-	if err != nil {
-		return fmt.Errorf("vote_consumer: 幂等检查失败 (msg_id: %s): %w", msg.MsgID, err)
-	}
-	if !ok {
-		log.Printf("重复投票消息，跳过: %s", msg.MsgID)
-		return nil
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return pendingVote{}, domain.Permanent(fmt.Errorf("vote_consumer: 反序列化投票消息失败: %w", err))
 	}
 
 	userID, _ := strconv.ParseInt(msg.UserID, 10, 64)
 	postID, _ := strconv.ParseInt(msg.PostID, 10, 64)
 
+	version := msg.Seq
+	if version == 0 {
+		version, _ = strconv.ParseInt(msg.MsgID, 10, 64)
+	}
+
 	// 使用领域模型校验
 	vote := &entity.Vote{
 		UserID:    userID,
 		PostID:    postID,
 		Direction: int8(msg.Action),
+		Version:   version,
 	}
 	if err := vote.Validate(); err != nil {
-		return fmt.Errorf("领域校验失败: %w", err)
+		return pendingVote{}, domain.Permanent(fmt.Errorf("领域校验失败: %w", err))
 	}
+	return pendingVote{msgID: msg.MsgID, vote: vote}, nil
+}
 
-	if err := c.voteRepo.SaveVote(ctx, userID, postID, int8(msg.Action)); err != nil {
-		c.rdb.Del(ctx, dedupKey) // 失败则删除去重 key，允许重试
-		return fmt.Errorf("vote_consumer: 保存投票数据失败: %w", err)
+// flush 对一批消息去重、合并后批量落库并确认
+//   - 去重：一次 Pipeline 对所有 msg_id 执行 SETNX，已处理过的消息只确认不落库
+//   - 合并：同一 (user, post) 只保留版本最新的操作；重试的旧消息可能晚于新消息到达，不能按投递顺序覆盖
+//   - 失败：删除本批写入的去重 key，每条消息各自进入重试队列，保证重试次数按消息独立累计
+func (c *VoteConsumer) flush(ctx context.Context, batch []pendingVote) {
+	// 一批消息来自不同的链路，落库 Span 作为新的根 Span，通过 Link 关联每条消息
+	links := make([]trace.Link, 0, len(batch))
+	for _, p := range batch {
+		links = append(links, p.link)
 	}
+	ctx, span := tracer.Start(ctx, QueueVote+" flush",
+		trace.WithNewRoot(),
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("messaging.batch.message_count", len(batch))),
+	)
+	defer span.End()
+
+	// 1. 幂等检查
+	dedupKeys := make([]string, len(batch))
+	cmds := make([]*redis.BoolCmd, len(batch))
+	pipe := c.rdb.Pipeline()
+	for i, p := range batch {
+		dedupKeys[i] = fmt.Sprintf("bluebell:mq:dedup:vote:%s", p.msgID)
+		cmds[i] = pipe.SetNX(ctx, dedupKeys[i], "1", voteDedupTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		// 部分 SETNX 可能已生效，删除本批写入的 key，避免重试时被误判为重复消息
+		var setKeys []string
+		for i, cmd := range cmds {
+			if cmd.Err() == nil && cmd.Val() {
+				setKeys = append(setKeys, dedupKeys[i])
+			}
+		}
+		if len(setKeys) > 0 {
+			c.rdb.Del(ctx, setKeys...)
+		}
+		c.retryAll(ctx, batch, fmt.Errorf("vote_consumer: 幂等检查失败: %w", err))
+		return
+	}
+
+	// 2. 按 (user, post) 合并，版本新的操作覆盖旧的
+	type votePair struct{ userID, postID int64 }
+	merged := make(map[votePair]*entity.Vote, len(batch))
+	order := make([]votePair, 0, len(batch))
+	fresh := make([]pendingVote, 0, len(batch))
+	freshKeys := make([]string, 0, len(batch))
+	for i, p := range batch {
+		if !cmds[i].Val() {
+			logger.WithContext(p.ctx).Info("duplicate vote message, skipped", zap.String("msg_id", p.msgID))
+			continue
+		}
+		fresh = append(fresh, p)
+		freshKeys = append(freshKeys, dedupKeys[i])
 
-	return nil
+		k := votePair{p.vote.UserID, p.vote.PostID}
+		prev, ok := merged[k]
+		if !ok {
+			order = append(order, k)
+		}
+		if !ok || p.vote.Version >= prev.Version {
+			merged[k] = p.vote
+		}
+	}
+
+	// 3. 单条多行 UPSERT 落库
+	if len(order) > 0 {
+		votes := make([]*entity.Vote, 0, len(order))
+		for _, k := range order {
+			votes = append(votes, merged[k])
+		}
+		if err := c.voteRepo.SaveVotes(ctx, votes); err != nil {
+			c.rdb.Del(ctx, freshKeys...) // 失败则删除去重 key，允许重试
+			c.retryAll(ctx, fresh, fmt.Errorf("vote_consumer: 保存投票数据失败: %w", err))
+			// 重复消息无需重试，逐条确认
+			for i, p := range batch {
+				if !cmds[i].Val() {
+					_ = p.delivery.Ack(false)
+				}
+			}
+			return
+		}
+	}
+
+	// 4. 批量确认：本批之前的消息均已确认（成功或已转入重试/死信），可安全使用 multiple=true
+	if err := batch[len(batch)-1].delivery.Ack(true); err != nil {
+		logger.WithContext(ctx).Error("ack vote batch failed", zap.Error(err))
+	}
+}
+
+// retryAll 将一批消息逐条交给重试策略处理
+func (c *VoteConsumer) retryAll(ctx context.Context, batch []pendingVote, cause error) {
+	logger.WithContext(ctx).Error("handle vote batch failed", zap.Int("batch", len(batch)), zap.Error(cause))
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(cause)
+	span.SetStatus(codes.Error, cause.Error())
+	for _, p := range batch {
+		if err := p.delivery.Retry(p.ctx, cause); err != nil {
+			logger.WithContext(p.ctx).Error("retry vote message failed", zap.Error(err))
+		}
+	}
 }
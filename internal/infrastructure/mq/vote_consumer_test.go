@@ -0,0 +1,195 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	memorymq "bluebell/internal/infrastructure/mq/memory"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeVoteRepo 记录每次批量写入，并按版本号条件更新模拟 vote 表
+type fakeVoteRepo struct {
+	domain.VoteRepository
+
+	mu      sync.Mutex
+	fails   int // 前 fails 次写入返回错误
+	batches [][]entity.Vote
+	rows    map[[2]int64]entity.Vote
+}
+
+func (r *fakeVoteRepo) SaveVotes(_ context.Context, votes []*entity.Vote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch := make([]entity.Vote, 0, len(votes))
+	for _, v := range votes {
+		batch = append(batch, *v)
+	}
+	r.batches = append(r.batches, batch)
+	if r.fails > 0 {
+		r.fails--
+		return errors.New("db down")
+	}
+	if r.rows == nil {
+		r.rows = make(map[[2]int64]entity.Vote)
+	}
+	for _, v := range votes {
+		k := [2]int64{v.UserID, v.PostID}
+		if cur, ok := r.rows[k]; !ok || v.Version >= cur.Version {
+			r.rows[k] = *v
+		}
+	}
+	return nil
+}
+
+func (r *fakeVoteRepo) calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.batches)
+}
+
+func (r *fakeVoteRepo) row(userID, postID int64) (entity.Vote, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.rows[[2]int64{userID, postID}]
+	return v, ok
+}
+
+type voteConsumerEnv struct {
+	broker *memorymq.Broker
+	repo   *fakeVoteRepo
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startVoteConsumer(t *testing.T, repo *fakeVoteRepo, backoff time.Duration, batchSize int, flushInterval time.Duration, before ...VoteMessage) *voteConsumerEnv {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	broker := memorymq.NewBroker(3, func(int) time.Duration { return backoff })
+	broker.Bind(ExchangeVote, RoutingKeyVote, QueueVote)
+	env := &voteConsumerEnv{broker: broker, repo: repo, done: make(chan struct{})}
+	for _, m := range before {
+		env.publish(t, m)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	env.cancel = cancel
+	c := NewVoteConsumer(broker, repo, rdb, batchSize, flushInterval)
+	go func() {
+		defer close(env.done)
+		_ = c.Start(ctx)
+	}()
+	t.Cleanup(env.stop)
+	return env
+}
+
+func (e *voteConsumerEnv) publish(t *testing.T, m VoteMessage) {
+	t.Helper()
+	body, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.broker.Publish(context.Background(), ExchangeVote, RoutingKeyVote, body); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+// stop 停止消费者，未确认的消息随订阅结束重新入队
+func (e *voteConsumerEnv) stop() {
+	e.cancel()
+	<-e.done
+}
+
+// assertQueueDrained 所有消息都已确认：重新订阅收不到任何消息
+func (e *voteConsumerEnv) assertQueueDrained(t *testing.T) {
+	t.Helper()
+	e.stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	deliveries, err := e.broker.Subscribe(ctx, QueueVote, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := <-deliveries; ok {
+		_ = d.Nack(false, true)
+		t.Errorf("unacked vote message left in queue: %s", d.Body())
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestVoteConsumer_CoalescesBatchByVersion(t *testing.T) {
+	repo := &fakeVoteRepo{}
+	// 一批恰好 4 条，只由批量大小触发落库
+	env := startVoteConsumer(t, repo, time.Millisecond, 4, time.Hour,
+		VoteMessage{MsgID: "10", UserID: "1", PostID: "100", Action: 1, Seq: 10},
+		VoteMessage{MsgID: "5", UserID: "1", PostID: "100", Action: -1, Seq: 5},  // 重试回来的旧投票，晚于新投票到达
+		VoteMessage{MsgID: "10", UserID: "1", PostID: "100", Action: 1, Seq: 10}, // 重复投递
+		VoteMessage{MsgID: "11", UserID: "2", PostID: "100", Action: 1},          // 旧格式消息，版本取 MsgID
+	)
+
+	waitFor(t, "batch flush", func() bool { return repo.calls() == 1 })
+	got := repo.batches[0]
+	want := []entity.Vote{
+		{UserID: 1, PostID: 100, Direction: 1, Version: 10},
+		{UserID: 2, PostID: 100, Direction: 1, Version: 11},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SaveVotes() got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SaveVotes()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	// 整批通过 Ack(multiple=true) 一次确认
+	env.assertQueueDrained(t)
+}
+
+func TestVoteConsumer_RetriedOlderVoteDoesNotOverwriteNewer(t *testing.T) {
+	repo := &fakeVoteRepo{fails: 1}
+	env := startVoteConsumer(t, repo, 100*time.Millisecond, 2, 10*time.Millisecond,
+		VoteMessage{MsgID: "20", UserID: "1", PostID: "100", Action: 1, Seq: 20},
+		VoteMessage{MsgID: "20", UserID: "1", PostID: "100", Action: 1, Seq: 20},
+	)
+
+	// 首批落库失败：新消息进入重试队列，重复消息直接确认
+	waitFor(t, "failed flush", func() bool { return repo.calls() == 1 })
+	// 重试等待期间用户改投反对票，新投票先落库
+	env.publish(t, VoteMessage{MsgID: "21", UserID: "1", PostID: "100", Action: -1, Seq: 21})
+	waitFor(t, "newer vote saved", func() bool { return repo.calls() == 2 })
+	// 旧投票重试后再次落库（去重 key 已删除），但不会覆盖新投票
+	waitFor(t, "retried vote saved", func() bool { return repo.calls() == 3 })
+
+	if retried := repo.batches[2]; len(retried) != 1 || retried[0].Version != 20 {
+		t.Errorf("retried batch = %+v, want the vote with version 20", retried)
+	}
+	if v, _ := repo.row(1, 100); v.Direction != -1 || v.Version != 21 {
+		t.Errorf("stored vote = %+v, want direction -1 version 21", v)
+	}
+	if dead := env.broker.DeadLetters(QueueVote); len(dead) != 0 {
+		t.Errorf("dead letters = %d, want 0", len(dead))
+	}
+	env.assertQueueDrained(t)
+}
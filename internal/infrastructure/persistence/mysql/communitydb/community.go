@@ -2,6 +2,7 @@ package communitydb
 
 import (
 	// 模型
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
 	"bluebell/internal/infrastructure/persistence/mysql/model"
 
 	// 领域层
@@ -52,7 +53,7 @@ func fromModelCommunity(m *model.Community) *entity.Community {
 // GetCommunityList 查询社区列表数据
 func (r *communityRepoStruct) GetCommunityList(ctx context.Context) (data []*entity.Community, err error) {
 	var mList []*model.Community
-	err = r.db.WithContext(ctx).Select("id", "community_name", "introduction").Find(&mList).Error
+	err = dbctx.DB(ctx, r.db).Select("id", "community_name", "introduction").Find(&mList).Error
 	if err != nil {
 		return nil, fmt.Errorf("查询社区列表失败: %w", err)
 	}
@@ -67,7 +68,7 @@ func (r *communityRepoStruct) GetCommunityList(ctx context.Context) (data []*ent
 // GetCommunityDetailByID 根据ID查询社区详情
 func (r *communityRepoStruct) GetCommunityDetailByID(ctx context.Context, id int64) (*entity.Community, error) {
 	m := new(model.Community)
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(m).Error
+	err := dbctx.DB(ctx, r.db).Where("id = ?", id).First(m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -80,7 +81,7 @@ func (r *communityRepoStruct) GetCommunityDetailByID(ctx context.Context, id int
 // CreateCommunity 创建新社区
 func (r *communityRepoStruct) CreateCommunity(ctx context.Context, community *entity.Community) error {
 	m := toModelCommunity(community)
-	err := r.db.WithContext(ctx).Create(m).Error
+	err := dbctx.DB(ctx, r.db).Create(m).Error
 	if err != nil {
 		return fmt.Errorf("创建社区失败: %w", err)
 	}
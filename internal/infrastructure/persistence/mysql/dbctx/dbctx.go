@@ -0,0 +1,32 @@
+// Package dbctx 通过 context 在仓储之间传递 GORM 事务句柄
+//
+// 应用层通过 domain.TransactionManager 开启事务后，事务句柄被放入 ctx，
+// 各仓储统一调用 DB(ctx, r.db) 获取句柄，从而在不感知事务的前提下共用同一个事务
+package dbctx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// WithTx 将事务句柄放入 context
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// InTx 判断 context 中是否已经携带事务
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
+
+// DB 优先返回 context 中的事务句柄，否则返回默认连接
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -2,7 +2,7 @@ package database
 
 import (
 	"bluebell/internal/config"
-	"bluebell/internal/infrastructure/persistence/mysql/model"
+	"bluebell/internal/infrastructure/metrics"
 	"context"
 	"fmt"
 	"log"
@@ -13,7 +13,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
-
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
 )
 
 // Init 初始化 MySQL 连接，返回数据库连接实例
@@ -67,8 +67,16 @@ func Init(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	// 注册 OpenTelemetry GORM 插件，自动为 SQL 操作创建子 Span
+	// 指标由下面的 metrics 插件负责；不记录查询参数，避免密码等敏感值进入链路数据
+	if err := db.Use(otelgorm.NewPlugin(otelgorm.WithoutMetrics(), otelgorm.WithoutQueryVariables())); err != nil {
+		return nil, fmt.Errorf("register gorm tracing plugin failed: %w", err)
+	}
+
+	// 注册指标插件，记录每条语句的耗时和失败数
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("register gorm metrics plugin failed: %w", err)
+	}
 
-	
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("get sql.DB failed: %w", err)
@@ -83,68 +91,12 @@ func Init(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetConnMaxIdleTime(10 * time.Minute)
 	sqlDB.SetConnMaxLifetime(2 * time.Hour)
 
-	// 5. 自动迁移 (AutoMigrate)
-	// 为什么：开发阶段自动创建/更新表结构，减少手动操作
-	err = db.AutoMigrate(
-		&model.User{},
-		&model.Community{},
-		&model.Post{},
-		&model.Vote{},
-		&model.Remark{},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("auto migrate failed: %w", err)
-	}
-
-	// 6. 数据初始化 (Seed Data)
-	if err := seedData(db); err != nil {
-		zap.L().Error("seed data failed", zap.Error(err))
-	}
-
+	// 表结构由版本化迁移维护（见 migrate 包和 cmd/migrate），初始数据由 migrate seed 显式写入
 	zap.L().Info("init mysql success", zap.String("dsn_host", mysqlCfg.Host))
 
 	return db, nil
 }
 
-// seedData 初始化基础数据
-func seedData(db *gorm.DB) error {
-	// 1. 初始化社区数据
-	var communityCount int64
-	db.Model(&model.Community{}).Count(&communityCount)
-	if communityCount == 0 {
-		communities := []model.Community{
-			{CommunityName: "Go", Introduction: "Golang is the best language!"},
-			{CommunityName: "Vue", Introduction: "Vue.js is a progressive JavaScript framework."},
-			{CommunityName: "LeetCode", Introduction: "Practice coding and prepare for interviews."},
-			{CommunityName: "Life", Introduction: "Everything about life outside of coding."},
-			{CommunityName: "Python", Introduction: "A versatile programming language for everyone."},
-			{CommunityName: "React", Introduction: "Build user interfaces with React."},
-		}
-		if err := db.Create(&communities).Error; err != nil {
-			return fmt.Errorf("seed communities failed: %w", err)
-		}
-		zap.L().Info("seed communities success")
-	}
-
-	// 2. 初始化管理员账号
-	var userCount int64
-	db.Model(&model.User{}).Count(&userCount)
-	if userCount == 0 {
-		// 创建管理员账号
-		adminUser := &model.User{
-			UserName: "admin",
-			Passwd:   "admin123", // 密码会被 BeforeCreate 钩子自动加密
-			Role:     model.RoleAdmin,
-		}
-		if err := db.Create(adminUser).Error; err != nil {
-			return fmt.Errorf("seed admin user failed: %w", err)
-		}
-		zap.L().Info("seed admin user success", zap.String("username", "admin"))
-	}
-
-	return nil
-}
-
 // Close 关闭 MySQL 连接
 func Close(db *gorm.DB) {
 	if db != nil {
@@ -0,0 +1,343 @@
+// Package migrate 版本化的 MySQL 表结构迁移
+//
+// 迁移文件位于 sql/ 目录并编译进二进制，命名为 {版本号}_{名称}.up.sql / .down.sql，版本号递增且不可复用。
+// 已执行的版本记录在 schema_migrations 表中；执行期间持有 MySQL 命名锁（GET_LOCK），
+// 多个副本同时启动迁移时只有一个执行，其余等待后发现已无待执行版本。
+//
+// MySQL 的 DDL 不支持事务回滚：某个版本执行到一半失败时，该版本会被标记为 dirty，
+// 之后的 up/down 都会拒绝执行，需要人工修复表结构并删除 schema_migrations 中对应的记录后重试
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// versionTable 记录已执行版本的表
+const versionTable = "schema_migrations"
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 单个版本的执行状态
+type Status struct {
+	Migration
+	Applied   bool
+	Dirty     bool // 执行中途失败，需要人工处理
+	AppliedAt time.Time
+}
+
+// ErrDirty 存在执行失败的版本，需要人工修复后才能继续迁移
+var ErrDirty = errors.New("database has a dirty migration")
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration // 按版本号升序
+	lockTimeout time.Duration
+}
+
+// New 使用编译进二进制的迁移文件创建执行器，lockTimeout 为等待其他进程释放迁移锁的最长时间
+func New(gormDB *gorm.DB, lockTimeout time.Duration) (*Migrator, error) {
+	db, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql.DB failed: %w", err)
+	}
+	sub, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, lockTimeout: lockTimeout}, nil
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load 读取目录中的迁移文件，每个版本必须同时有 up 和 down 文件
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations failed: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s failed: %w", e.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up 按顺序执行所有未执行的版本，返回本次执行的版本
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			if s.Applied {
+				continue
+			}
+			if err := m.apply(ctx, conn, s.Migration); err != nil {
+				return err
+			}
+			applied = append(applied, s.Migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 从最新版本开始回滚 steps 个已执行的版本，返回本次回滚的版本
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		status, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(status) - 1; i >= 0 && len(reverted) < steps; i-- {
+			if !status[i].Applied {
+				continue
+			}
+			if err := m.revert(ctx, conn, status[i].Migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, status[i].Migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 返回所有版本的执行状态，不加锁
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return m.statusUnchecked(ctx, conn)
+}
+
+// Pending 返回未执行的版本
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range status {
+		if !s.Applied {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// withLock 在同一个连接上持有命名锁执行 fn；GET_LOCK 是会话级的，释放也必须在同一连接上
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var got sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(CONCAT(?, DATABASE()), ?)",
+		versionTable+":", int(m.lockTimeout.Seconds())).Scan(&got)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock failed: %w", err)
+	}
+	if got.Int64 != 1 {
+		return fmt.Errorf("acquire migration lock failed: timed out after %s", m.lockTimeout)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT RELEASE_LOCK(CONCAT(?, DATABASE()))", versionTable+":")
+
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `"+versionTable+"` ("+
+		"`version` bigint NOT NULL, "+
+		"`name` varchar(255) NOT NULL, "+
+		"`dirty` tinyint(1) NOT NULL DEFAULT 0, "+
+		"`applied_at` datetime(3) NOT NULL, "+
+		"PRIMARY KEY (`version`)"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4")
+	if err != nil {
+		return fmt.Errorf("create %s table failed: %w", versionTable, err)
+	}
+	return nil
+}
+
+// status 读取执行状态，存在 dirty 版本时返回 ErrDirty
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	status, err := m.statusUnchecked(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range status {
+		if s.Dirty {
+			return nil, fmt.Errorf("%w: version %d (%s) failed halfway; fix the schema manually, then delete its row from %s",
+				ErrDirty, s.Version, s.Name, versionTable)
+		}
+	}
+	return status, nil
+}
+
+func (m *Migrator) statusUnchecked(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT `version`, `dirty`, `applied_at` FROM `"+versionTable+"`")
+	if err != nil {
+		return nil, fmt.Errorf("query %s failed: %w", versionTable, err)
+	}
+	defer rows.Close()
+
+	type record struct {
+		dirty     bool
+		appliedAt time.Time
+	}
+	records := make(map[int64]record)
+	for rows.Next() {
+		var (
+			version int64
+			r       record
+		)
+		if err := rows.Scan(&version, &r.dirty, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		records[version] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	status := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		r, ok := records[mig.Version]
+		status = append(status, Status{Migration: mig, Applied: ok && !r.dirty, Dirty: r.dirty, AppliedAt: r.appliedAt})
+	}
+	return status, nil
+}
+
+// apply 执行一个版本：先写入 dirty 记录，全部语句成功后清除 dirty 标记
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if _, err := conn.ExecContext(ctx, "INSERT INTO `"+versionTable+"` (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 1, ?)",
+		mig.Version, mig.Name, time.Now()); err != nil {
+		return fmt.Errorf("record migration %d failed: %w", mig.Version, err)
+	}
+	if err := execScript(ctx, conn, mig.Up); err != nil {
+		return fmt.Errorf("migration %d_%s up failed: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := conn.ExecContext(ctx, "UPDATE `"+versionTable+"` SET `dirty` = 0, `applied_at` = ? WHERE `version` = ?",
+		time.Now(), mig.Version); err != nil {
+		return fmt.Errorf("record migration %d failed: %w", mig.Version, err)
+	}
+	return nil
+}
+
+// revert 回滚一个版本：先标记 dirty，全部语句成功后删除记录
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if _, err := conn.ExecContext(ctx, "UPDATE `"+versionTable+"` SET `dirty` = 1 WHERE `version` = ?", mig.Version); err != nil {
+		return fmt.Errorf("record migration %d failed: %w", mig.Version, err)
+	}
+	if err := execScript(ctx, conn, mig.Down); err != nil {
+		return fmt.Errorf("migration %d_%s down failed: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := conn.ExecContext(ctx, "DELETE FROM `"+versionTable+"` WHERE `version` = ?", mig.Version); err != nil {
+		return fmt.Errorf("record migration %d failed: %w", mig.Version, err)
+	}
+	return nil
+}
+
+// execScript 逐条执行脚本中的语句（驱动默认不允许一次执行多条语句）
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w\nstatement: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// splitStatements 按行尾分号拆分语句，忽略 -- 注释行
+// 迁移文件约定每条语句以行尾的分号结束，语句内部（如字符串常量）不出现行尾分号
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		buf   strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(buf.String()), ";"))
+			buf.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
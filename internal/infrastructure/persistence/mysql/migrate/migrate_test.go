@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	sub, err := fs.Sub(files, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	for i, m := range migrations {
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("migrations not sorted: %d after %d", m.Version, migrations[i-1].Version)
+		}
+		if len(splitStatements(m.Up)) == 0 || len(splitStatements(m.Down)) == 0 {
+			t.Errorf("migration %d_%s has an empty script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"0001_init.up.sql": {Data: []byte("CREATE TABLE a (id int);")},
+		},
+		"bad name": {
+			"init.sql": {Data: []byte("CREATE TABLE a (id int);")},
+		},
+		"conflicting names": {
+			"0001_init.up.sql":    {Data: []byte("CREATE TABLE a (id int);")},
+			"0001_other.down.sql": {Data: []byte("DROP TABLE a;")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load() should fail", name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+  id int
+);
+
+ALTER TABLE a
+  ADD INDEX idx (id);
+DROP TABLE b`
+
+	want := []string{
+		"CREATE TABLE a (\n  id int\n)",
+		"ALTER TABLE a\n  ADD INDEX idx (id)",
+		"DROP TABLE b",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitStatements() = %q, want %q", got, want)
+	}
+}
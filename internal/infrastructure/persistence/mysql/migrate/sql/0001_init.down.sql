@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `outbox`;
+DROP TABLE IF EXISTS `remark`;
+DROP TABLE IF EXISTS `vote`;
+DROP TABLE IF EXISTS `post`;
+DROP TABLE IF EXISTS `community`;
+DROP TABLE IF EXISTS `user`;
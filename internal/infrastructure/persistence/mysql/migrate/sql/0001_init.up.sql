@@ -0,0 +1,82 @@
+-- 初始表结构，与此前 AutoMigrate 生成的结构一致
+-- 使用 IF NOT EXISTS，已由 AutoMigrate 建表的库执行后只会记录版本
+
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `user_id` bigint NULL,
+  `user_name` varchar(64) NOT NULL,
+  `passwd` varchar(255) NOT NULL,
+  `role` bigint NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `community` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `community_name` varchar(255) NOT NULL,
+  `introduction` text NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_community_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `post` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `post_id` varchar(255) NOT NULL,
+  `author_id` bigint NULL,
+  `community_id` bigint NULL,
+  `post_title` text NOT NULL,
+  `content` text NOT NULL,
+  `status` tinyint NULL,
+  PRIMARY KEY (`id`, `post_id`),
+  INDEX `idx_post_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `vote` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `post_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `direction` tinyint NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_post_user` (`post_id`, `user_id`),
+  INDEX `idx_vote_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `remark` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `post_id` bigint NOT NULL,
+  `content` text NOT NULL,
+  `author_id` bigint NOT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_remark_post_id` (`post_id`),
+  INDEX `idx_remark_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `exchange` varchar(128) NOT NULL,
+  `routing_key` varchar(128) NOT NULL,
+  `payload` mediumblob NOT NULL,
+  `headers` text,
+  `status` tinyint NOT NULL DEFAULT 0,
+  `attempts` bigint NOT NULL DEFAULT 0,
+  `last_error` text,
+  `created_at` datetime(3) NULL,
+  `sent_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_outbox_status_id` (`status`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
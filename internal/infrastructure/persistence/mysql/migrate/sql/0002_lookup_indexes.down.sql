@@ -0,0 +1,5 @@
+ALTER TABLE `user`
+  DROP INDEX `idx_user_user_id`,
+  DROP INDEX `idx_user_user_name`;
+
+ALTER TABLE `post` DROP INDEX `uk_post_post_id`;
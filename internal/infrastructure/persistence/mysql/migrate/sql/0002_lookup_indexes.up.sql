@@ -0,0 +1,7 @@
+-- 帖子详情按 post_id 查询，而主键是 (id, post_id)，此前按 post_id 查询需要全表扫描
+ALTER TABLE `post` ADD UNIQUE INDEX `uk_post_post_id` (`post_id`);
+
+-- 登录、注册按用户名查询，刷新 Token、预加载作者按 user_id 查询
+ALTER TABLE `user`
+  ADD INDEX `idx_user_user_name` (`user_name`),
+  ADD INDEX `idx_user_user_id` (`user_id`);
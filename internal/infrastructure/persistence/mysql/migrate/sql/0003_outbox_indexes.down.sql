@@ -0,0 +1,4 @@
+ALTER TABLE `outbox`
+  DROP INDEX `idx_outbox_status_sent_at`,
+  DROP INDEX `idx_outbox_status_id`,
+  ADD INDEX `idx_outbox_status_id` (`status`, `created_at`);
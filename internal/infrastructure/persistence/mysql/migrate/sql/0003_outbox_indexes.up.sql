@@ -0,0 +1,6 @@
+-- 中继按 status = 0 ORDER BY id 取批次，原索引第二列是 created_at，排序仍需 filesort
+ALTER TABLE `outbox`
+  DROP INDEX `idx_outbox_status_id`,
+  ADD INDEX `idx_outbox_status_id` (`status`, `id`),
+  -- 定期清理按 status = 1 AND sent_at < ? 删除过期的已投递消息
+  ADD INDEX `idx_outbox_status_sent_at` (`status`, `sent_at`);
@@ -0,0 +1,3 @@
+-- 中继在短事务中领取消息并写入租约到期时间，投递和等待确认在事务之外进行；
+-- 租约期内其他实例不再领取，中继崩溃后租约到期即可被重新领取
+ALTER TABLE `outbox` ADD COLUMN `locked_until` datetime(3) NULL AFTER `attempts`;
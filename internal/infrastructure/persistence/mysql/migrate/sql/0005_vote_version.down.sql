@@ -0,0 +1 @@
+ALTER TABLE `vote` DROP COLUMN `version`;
@@ -0,0 +1,3 @@
+-- 投票消息可能因重试乱序落库，记录最近一次生效投票的版本号（Snowflake ID），
+-- 批量 UPSERT 只接受版本不低于当前记录的投票，避免旧投票覆盖新投票
+ALTER TABLE `vote` ADD COLUMN `version` bigint NOT NULL DEFAULT 0 AFTER `direction`;
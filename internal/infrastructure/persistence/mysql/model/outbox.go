@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// OutboxMessage 发件箱消息模型
+// 对应数据库中的 outbox 表，不需要软删除，因此不嵌入 gorm.Model
+type OutboxMessage struct {
+	ID          int64      `gorm:"column:id;primaryKey;autoIncrement;index:idx_outbox_status_id,priority:2"`
+	Exchange    string     `gorm:"column:exchange;size:128;not null"`
+	RoutingKey  string     `gorm:"column:routing_key;size:128;not null"`
+	Payload     []byte     `gorm:"column:payload;type:mediumblob;not null"`
+	Headers     string     `gorm:"column:headers;type:text"` // JSON 格式的消息头
+	Status      int8       `gorm:"column:status;not null;default:0;index:idx_outbox_status_id,priority:1;index:idx_outbox_status_sent_at,priority:1"`
+	Attempts    int        `gorm:"column:attempts;not null;default:0"`
+	LockedUntil *time.Time `gorm:"column:locked_until"` // 中继领取后的租约到期时间，到期前其他实例不会领取
+	LastError   string     `gorm:"column:last_error;type:text"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	SentAt      *time.Time `gorm:"column:sent_at;index:idx_outbox_status_sent_at,priority:2"`
+}
+
+// TableName 自定义表名
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -8,7 +8,8 @@ type Vote struct {
 	gorm.Model
 	PostID    int64 `gorm:"column:post_id;not null;index:idx_post_user,unique"`
 	UserID    int64 `gorm:"column:user_id;not null;index:idx_post_user,unique"`
-	Direction int8  `gorm:"column:direction;not null"` // 1: 赞成, -1: 反对, 0: 取消
+	Direction int8  `gorm:"column:direction;not null"`         // 1: 赞成, -1: 反对, 0: 取消
+	Version   int64 `gorm:"column:version;not null;default:0"` // 最近一次生效投票的版本号，旧版本的投票不会覆盖
 }
 
 // TableName 自定义表名
@@ -0,0 +1,154 @@
+package outboxdb
+
+import (
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
+	"bluebell/internal/infrastructure/persistence/mysql/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxRepoStruct 发件箱数据访问实现
+type outboxRepoStruct struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepo 创建 outboxRepoStruct 实例
+func NewOutboxRepo(db *gorm.DB) domain.OutboxRepository {
+	return &outboxRepoStruct{db: db}
+}
+
+// fromModelOutbox 将数据库模型转换为领域实体
+func fromModelOutbox(m *model.OutboxMessage) *entity.OutboxMessage {
+	msg := &entity.OutboxMessage{
+		ID:         m.ID,
+		Exchange:   m.Exchange,
+		RoutingKey: m.RoutingKey,
+		Payload:    m.Payload,
+		Status:     m.Status,
+		Attempts:   m.Attempts,
+		CreatedAt:  m.CreatedAt,
+	}
+	// 消息头只用于链路追踪，解析失败时丢弃而不影响投递
+	if m.Headers != "" {
+		_ = json.Unmarshal([]byte(m.Headers), &msg.Headers)
+	}
+	return msg
+}
+
+// Add 写入一条待投递消息
+func (r *outboxRepoStruct) Add(ctx context.Context, msg *entity.OutboxMessage) error {
+	m := &model.OutboxMessage{
+		Exchange:   msg.Exchange,
+		RoutingKey: msg.RoutingKey,
+		Payload:    msg.Payload,
+		Status:     entity.OutboxStatusPending,
+	}
+	if len(msg.Headers) > 0 {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return fmt.Errorf("序列化发件箱消息头失败: %w", err)
+		}
+		m.Headers = string(headers)
+	}
+	if err := dbctx.DB(ctx, r.db).Create(m).Error; err != nil {
+		return fmt.Errorf("写入发件箱失败: %w", err)
+	}
+	msg.ID = m.ID
+	return nil
+}
+
+// FetchPending 使用 FOR UPDATE SKIP LOCKED 锁定一批未被领取的待投递消息
+// 多个实例同时运行中继任务时，各自拿到互不重叠的批次；随后在同一事务中调用 Lease 领取
+func (r *outboxRepoStruct) FetchPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	var mList []*model.OutboxMessage
+	err := dbctx.DB(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ?", entity.OutboxStatusPending).
+		Where("(locked_until IS NULL OR locked_until < ?)", time.Now()).
+		Order("id").
+		Limit(limit).
+		Find(&mList).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询待投递消息失败: %w", err)
+	}
+
+	msgs := make([]*entity.OutboxMessage, 0, len(mList))
+	for _, m := range mList {
+		msgs = append(msgs, fromModelOutbox(m))
+	}
+	return msgs, nil
+}
+
+// Lease 写入租约到期时间
+func (r *outboxRepoStruct) Lease(ctx context.Context, ids []int64, until time.Time) error {
+	return r.setLockedUntil(ctx, ids, &until)
+}
+
+// Release 清除租约
+func (r *outboxRepoStruct) Release(ctx context.Context, ids []int64) error {
+	return r.setLockedUntil(ctx, ids, nil)
+}
+
+func (r *outboxRepoStruct) setLockedUntil(ctx context.Context, ids []int64, until *time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := dbctx.DB(ctx, r.db).Model(&model.OutboxMessage{}).
+		Where("id IN ?", ids).
+		Update("locked_until", until).Error
+	if err != nil {
+		return fmt.Errorf("更新发件箱租约失败: %w", err)
+	}
+	return nil
+}
+
+// MarkSent 将消息标记为已投递
+func (r *outboxRepoStruct) MarkSent(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := dbctx.DB(ctx, r.db).Model(&model.OutboxMessage{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":       entity.OutboxStatusSent,
+			"sent_at":      time.Now(),
+			"locked_until": nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("标记消息已投递失败: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 记录一次投递失败
+// 注意：MySQL 按从左到右的顺序计算 SET 子句，status 必须写在 attempts 自增之前
+func (r *outboxRepoStruct) MarkFailed(ctx context.Context, id int64, reason string, maxAttempts int) error {
+	err := dbctx.DB(ctx, r.db).Exec(
+		"UPDATE outbox SET status = CASE WHEN attempts + 1 >= ? THEN ? ELSE status END, attempts = attempts + 1, last_error = ?, locked_until = NULL WHERE id = ?",
+		maxAttempts, entity.OutboxStatusFailed, reason, id,
+	).Error
+	if err != nil {
+		return fmt.Errorf("记录消息投递失败 (id: %d): %w", id, err)
+	}
+	return nil
+}
+
+// DeleteSent 删除 before 之前投递成功的消息
+// 按 id 顺序限量删除，避免单条语句长时间持有大量行锁
+func (r *outboxRepoStruct) DeleteSent(ctx context.Context, before time.Time, limit int) (int64, error) {
+	res := dbctx.DB(ctx, r.db).Exec(
+		"DELETE FROM outbox WHERE status = ? AND sent_at < ? ORDER BY id LIMIT ?",
+		entity.OutboxStatusSent, before, limit,
+	)
+	if res.Error != nil {
+		return 0, fmt.Errorf("清理已投递消息失败: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
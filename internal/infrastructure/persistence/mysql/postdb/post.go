@@ -3,6 +3,7 @@ package postdb
 import (
 	// 模型
 	"bluebell/internal/infrastructure/persistence/mysql/model"
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
 
 	// 领域层
 	"bluebell/internal/domain"
@@ -13,6 +14,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -79,10 +81,12 @@ func fromModelPost(m *model.Post) *entity.Post {
 // CreatePost 创建帖子
 func (r *postRepoStruct) CreatePost(ctx context.Context, post *entity.Post) (err error) {
 	m := toModelPost(post)
-	err = r.db.WithContext(ctx).Create(m).Error
+	err = dbctx.DB(ctx, r.db).Create(m).Error
 	if err != nil {
 		return fmt.Errorf("创建帖子失败: %w", err)
 	}
+	// 回填数据库生成的创建时间，保证下游（如搜索索引）与数据库一致
+	post.CreatedAt = m.CreatedAt
 	return nil
 }
 
@@ -90,7 +94,7 @@ func (r *postRepoStruct) CreatePost(ctx context.Context, post *entity.Post) (err
 func (r *postRepoStruct) GetPostByID(ctx context.Context, pid int64) (*entity.Post, error) {
 	m := new(model.Post)
 
-	err := r.db.WithContext(ctx).Preload("Author").
+	err := dbctx.DB(ctx, r.db).Preload("Author").
 		Preload("Community").
 		Where("post_id = ?", pid).
 		Where("status = ?", entity.PostStatusPublished).
@@ -113,7 +117,7 @@ func (r *postRepoStruct) GetPostListByIDsWithPreload(ctx context.Context, ids []
 
 	var mPosts []*model.Post
 
-	err = r.db.WithContext(ctx).Preload("Author").
+	err = dbctx.DB(ctx, r.db).Preload("Author").
 		Preload("Community").
 		Where("post_id IN ?", ids).
 		Where("status = ?", entity.PostStatusPublished).
@@ -141,7 +145,7 @@ func (r *postRepoStruct) GetPostListByIDsWithPreload(ctx context.Context, ids []
 
 // DeletePostByAuthor 软删除帖子（带作者验证）
 func (r *postRepoStruct) DeletePostByAuthor(ctx context.Context, postID, authorID int64) error {
-	result := r.db.WithContext(ctx).Model(&model.Post{}).
+	result := dbctx.DB(ctx, r.db).Model(&model.Post{}).
 		Where("post_id = ?", postID).
 		Where("author_id = ?", authorID).
 		Where("status = ?", entity.PostStatusPublished).
@@ -156,6 +160,36 @@ func (r *postRepoStruct) DeletePostByAuthor(ctx context.Context, postID, authorI
 	return nil
 }
 
+// ScanPosts 按自增主键做游标分页遍历帖子，避免 OFFSET 深分页
+func (r *postRepoStruct) ScanPosts(ctx context.Context, updatedSince time.Time, batchSize int, fn func(posts []*entity.Post) error) error {
+	var lastID uint
+	for {
+		var rows []*model.Post
+		q := dbctx.DB(ctx, r.db).Where("id > ?", lastID)
+		if !updatedSince.IsZero() {
+			q = q.Where("updated_at >= ?", updatedSince)
+		}
+		if err := q.Order("id").Limit(batchSize).Find(&rows).Error; err != nil {
+			return fmt.Errorf("遍历帖子失败: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		posts := make([]*entity.Post, 0, len(rows))
+		for _, m := range rows {
+			posts = append(posts, fromModelPost(m))
+		}
+		if err := fn(posts); err != nil {
+			return err
+		}
+
+		if len(rows) < batchSize {
+			return nil
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
 
 // DB 返回底层 GORM DB 实例，用于事务操作
 func (r *postRepoStruct) DB() *gorm.DB {
@@ -2,6 +2,7 @@ package postdb
 
 import (
 	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
 	"bluebell/internal/infrastructure/persistence/mysql/model"
 	"context"
 	"fmt"
@@ -48,17 +49,18 @@ func fromModelRemark(m *model.Remark) *entity.Remark {
 // CreateRemark 实现 dbdomain.RemarkRepository 接口
 func (r *postRepoStruct) CreateRemark(ctx context.Context, remark *entity.Remark) error {
 	m := toModelRemark(remark)
-	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+	if err := dbctx.DB(ctx, r.db).Create(m).Error; err != nil {
 		return fmt.Errorf("create remark failed: %w", err)
 	}
 	remark.ID = m.ID
+	remark.CreatedAt = m.CreatedAt
 	return nil
 }
 
 // GetRemarksByPostID 获取帖子的评论列表
 func (r *postRepoStruct) GetRemarksByPostID(ctx context.Context, postID int64) ([]*entity.Remark, error) {
 	var mRemarks []*model.Remark
-	if err := r.db.WithContext(ctx).
+	if err := dbctx.DB(ctx, r.db).
 		Where("post_id = ?", postID).
 		Preload("Author"). // 预加载作者，以便获取作者名
 		Order("created_at DESC").
@@ -75,7 +77,7 @@ func (r *postRepoStruct) GetRemarksByPostID(ctx context.Context, postID int64) (
 
 // DeleteRemarkByID 根据评论ID删除评论（软删除，利用 gorm.Model 的 DeletedAt 字段）
 func (r *postRepoStruct) DeleteRemarkByID(ctx context.Context, remarkID uint) error {
-	if err := r.db.WithContext(ctx).Delete(&model.Remark{}, remarkID).Error; err != nil {
+	if err := dbctx.DB(ctx, r.db).Delete(&model.Remark{}, remarkID).Error; err != nil {
 		return fmt.Errorf("delete remark failed: %w", err)
 	}
 	return nil
@@ -83,7 +85,7 @@ func (r *postRepoStruct) DeleteRemarkByID(ctx context.Context, remarkID uint) er
 
 // DeleteRemarksByPostID 删除指定帖子的所有评论（用于级联删除）
 func (r *postRepoStruct) DeleteRemarksByPostID(ctx context.Context, postID int64) error {
-	if err := r.db.WithContext(ctx).Where("post_id = ?", postID).Delete(&model.Remark{}).Error; err != nil {
+	if err := dbctx.DB(ctx, r.db).Where("post_id = ?", postID).Delete(&model.Remark{}).Error; err != nil {
 		return fmt.Errorf("delete remarks by post_id failed: %w", err)
 	}
 	return nil
@@ -3,6 +3,7 @@ package database
 import (
 	// DAO 层 - MySQL 数据库访问
 	"bluebell/internal/infrastructure/persistence/mysql/communitydb"
+	"bluebell/internal/infrastructure/persistence/mysql/outboxdb"
 	"bluebell/internal/infrastructure/persistence/mysql/postdb"
 	"bluebell/internal/infrastructure/persistence/mysql/userdb"
 	"bluebell/internal/infrastructure/persistence/mysql/votedb"
@@ -20,6 +21,8 @@ type Repositories struct {
 	User      domain.UserRepository
 	Vote      domain.VoteRepository
 	Remark    domain.RemarkRepository
+	Outbox    domain.OutboxRepository
+	Tx        domain.TransactionManager
 }
 
 // NewRepositories 创建 Repositories 实例
@@ -30,5 +33,7 @@ func NewRepositories(db *gorm.DB) *Repositories {
 		Community: communitydb.NewCommunityRepo(db),
 		User:      userdb.NewUserRepo(db),
 		Vote:      votedb.NewVoteRepo(db),
+		Outbox:    outboxdb.NewOutboxRepo(db),
+		Tx:        NewTransactionManager(db),
 	}
 }
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/persistence/mysql/model"
+	"bluebell/internal/infrastructure/persistence/mysql/userdb"
+	"bluebell/internal/infrastructure/snowflake"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SeedOptions 初始数据选项
+type SeedOptions struct {
+	DefaultCommunities bool   // 社区表为空时写入默认社区
+	AdminUsername      string // 管理员用户名，已存在时跳过
+	AdminPassword      string // 管理员密码，为空时不创建管理员
+}
+
+// defaultCommunities 默认社区
+var defaultCommunities = []model.Community{
+	{CommunityName: "Go", Introduction: "Golang is the best language!"},
+	{CommunityName: "Vue", Introduction: "Vue.js is a progressive JavaScript framework."},
+	{CommunityName: "LeetCode", Introduction: "Practice coding and prepare for interviews."},
+	{CommunityName: "Life", Introduction: "Everything about life outside of coding."},
+	{CommunityName: "Python", Introduction: "A versatile programming language for everyone."},
+	{CommunityName: "React", Introduction: "Build user interfaces with React."},
+}
+
+// Seed 写入初始数据，可重复执行：已有的数据不会被覆盖或重复写入
+// 需要先完成表结构迁移并初始化 snowflake
+func Seed(ctx context.Context, db *gorm.DB, opts SeedOptions) error {
+	db = db.WithContext(ctx)
+
+	// 1. 初始化社区数据
+	if opts.DefaultCommunities {
+		var communityCount int64
+		if err := db.Model(&model.Community{}).Count(&communityCount).Error; err != nil {
+			return fmt.Errorf("count communities failed: %w", err)
+		}
+		if communityCount == 0 {
+			communities := append([]model.Community(nil), defaultCommunities...)
+			if err := db.Create(&communities).Error; err != nil {
+				return fmt.Errorf("seed communities failed: %w", err)
+			}
+			zap.L().Info("seed communities success", zap.Int("count", len(communities)))
+		}
+	}
+
+	// 2. 初始化管理员账号
+	if opts.AdminPassword == "" {
+		zap.L().Info("admin password not provided, skip seeding admin user")
+		return nil
+	}
+	users := userdb.NewUserRepo(db)
+	if err := users.CheckUserExist(ctx, opts.AdminUsername); err != nil {
+		if errors.Is(err, entity.ErrUserExist) {
+			zap.L().Info("admin user already exists, skip", zap.String("username", opts.AdminUsername))
+			return nil
+		}
+		return err
+	}
+	hashed, err := entity.HashPassword(opts.AdminPassword)
+	if err != nil {
+		return fmt.Errorf("hash admin password failed: %w", err)
+	}
+	admin := &entity.User{
+		UserID:   snowflake.GenID(),
+		UserName: opts.AdminUsername,
+		Password: hashed,
+		Role:     entity.RoleAdmin,
+	}
+	if err := users.InsertUser(ctx, admin); err != nil {
+		return fmt.Errorf("seed admin user failed: %w", err)
+	}
+	zap.L().Info("seed admin user success", zap.String("username", opts.AdminUsername))
+	return nil
+}
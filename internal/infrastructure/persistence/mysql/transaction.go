@@ -0,0 +1,30 @@
+package database
+
+import (
+	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txManagerStruct 基于 GORM 的事务管理器实现
+type txManagerStruct struct {
+	db *gorm.DB
+}
+
+// NewTransactionManager 创建事务管理器
+func NewTransactionManager(db *gorm.DB) domain.TransactionManager {
+	return &txManagerStruct{db: db}
+}
+
+// WithinTx 在事务中执行 fn，事务句柄通过 ctx 传递给各仓储
+// 已处于事务中时直接复用外层事务，不开启嵌套事务
+func (m *txManagerStruct) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if dbctx.InTx(ctx) {
+		return fn(ctx)
+	}
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(dbctx.WithTx(ctx, tx))
+	})
+}
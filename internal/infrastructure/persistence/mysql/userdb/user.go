@@ -2,6 +2,7 @@ package userdb
 
 import (
 	// 模型
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
 	"bluebell/internal/infrastructure/persistence/mysql/model"
 
 	// 领域层
@@ -30,7 +31,7 @@ func NewUserRepo(db *gorm.DB) domain.UserRepository {
 // CheckUserExist 检查指定用户名的用户是否存在
 func (r *userRepoStruct) CheckUserExist(ctx context.Context, username string) (err error) {
 	var count int64
-	err = r.db.WithContext(ctx).Model(&model.User{}).Where("user_name = ?", username).Count(&count).Error
+	err = dbctx.DB(ctx, r.db).Model(&model.User{}).Where("user_name = ?", username).Count(&count).Error
 	if err != nil {
 		return fmt.Errorf("查询用户失败: %w", err)
 	}
@@ -69,7 +70,7 @@ func fromModelUser(m *model.User) *entity.User {
 // InsertUser 插入新用户
 func (r *userRepoStruct) InsertUser(ctx context.Context, user *entity.User) (err error) {
 	m := toModelUser(user)
-	err = r.db.WithContext(ctx).Create(m).Error
+	err = dbctx.DB(ctx, r.db).Create(m).Error
 	if err != nil {
 		return fmt.Errorf("插入用户失败: %w", err)
 	}
@@ -78,7 +79,7 @@ func (r *userRepoStruct) InsertUser(ctx context.Context, user *entity.User) (err
 
 func (r *userRepoStruct) VerifyUser(ctx context.Context, user *entity.User) (err error) {
 	m := &model.User{}
-	err = r.db.WithContext(ctx).Where("user_name = ?", user.UserName).First(m).Error
+	err = dbctx.DB(ctx, r.db).Where("user_name = ?", user.UserName).First(m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return entity.ErrUserNotExist
@@ -99,7 +100,7 @@ func (r *userRepoStruct) VerifyUser(ctx context.Context, user *entity.User) (err
 // CheckUserExistsByID 根据用户ID查询用户信息
 func (r *userRepoStruct) CheckUserExistsByID(ctx context.Context, uid int64) (*entity.User, error) {
 	m := &model.User{}
-	err := r.db.WithContext(ctx).Where("user_id = ?", uid).First(m).Error
+	err := dbctx.DB(ctx, r.db).Where("user_id = ?", uid).First(m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -116,7 +117,7 @@ func (r *userRepoStruct) GetUsersByIDs(ctx context.Context, ids []int64) (users
 	}
 
 	var mUsers []*model.User
-	err = r.db.WithContext(ctx).Where("user_id IN ?", ids).Find(&mUsers).Error
+	err = dbctx.DB(ctx, r.db).Where("user_id IN ?", ids).Find(&mUsers).Error
 	if err != nil {
 		return nil, fmt.Errorf("批量查询用户失败: %w", err)
 	}
@@ -131,7 +132,7 @@ func (r *userRepoStruct) GetUsersByIDs(ctx context.Context, ids []int64) (users
 // GetUserRoleByID 根据用户ID查询用户角色
 func (r *userRepoStruct) GetUserRoleByID(ctx context.Context, uid int64) (int, error) {
 	m := &model.User{}
-	err := r.db.WithContext(ctx).Select("role").Where("user_id = ?", uid).First(m).Error
+	err := dbctx.DB(ctx, r.db).Select("role").Where("user_id = ?", uid).First(m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return 0, entity.ErrUserNotExist
@@ -144,7 +145,7 @@ func (r *userRepoStruct) GetUserRoleByID(ctx context.Context, uid int64) (int, e
 // GetUserByUsername 根据用户名获取用户信息
 func (r *userRepoStruct) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	m := &model.User{}
-	err := r.db.WithContext(ctx).Where("user_name = ?", username).First(m).Error
+	err := dbctx.DB(ctx, r.db).Where("user_name = ?", username).First(m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, entity.ErrUserNotExist
@@ -2,6 +2,8 @@ package votedb
 
 import (
 	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/persistence/mysql/dbctx"
 	"bluebell/internal/infrastructure/persistence/mysql/model"
 	"context"
 	"fmt"
@@ -26,7 +28,7 @@ func (r *voteRepoStruct) SaveVote(ctx context.Context, userID, postID int64, dir
 	}
 
 	// 使用 Upsert (ON DUPLICATE KEY UPDATE)
-	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+	err := dbctx.DB(ctx, r.db).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"direction", "updated_at"}),
 	}).Create(vote).Error
@@ -36,3 +38,35 @@ func (r *voteRepoStruct) SaveVote(ctx context.Context, userID, postID int64, dir
 	}
 	return nil
 }
+
+func (r *voteRepoStruct) SaveVotes(ctx context.Context, votes []*entity.Vote) error {
+	if len(votes) == 0 {
+		return nil
+	}
+	rows := make([]model.Vote, 0, len(votes))
+	for _, v := range votes {
+		rows = append(rows, model.Vote{
+			UserID:    v.UserID,
+			PostID:    v.PostID,
+			Direction: v.Direction,
+			Version:   v.Version,
+		})
+	}
+
+	// 多行 INSERT ... ON DUPLICATE KEY UPDATE，一次往返写入整批
+	// 只有版本不低于当前记录的投票才生效；MySQL 按书写顺序赋值，version 必须最后更新
+	newer := "VALUES(`version`) >= `version`"
+	err := dbctx.DB(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "direction"}, Value: gorm.Expr("IF(" + newer + ", VALUES(`direction`), `direction`)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("IF(" + newer + ", VALUES(`updated_at`), `updated_at`)")},
+			{Column: clause.Column{Name: "version"}, Value: gorm.Expr("GREATEST(`version`, VALUES(`version`))")},
+		},
+	}).Create(&rows).Error
+
+	if err != nil {
+		return fmt.Errorf("批量保存投票数据失败: %w", err)
+	}
+	return nil
+}
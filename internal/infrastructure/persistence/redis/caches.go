@@ -2,6 +2,7 @@
 package cache
 
 import (
+	idempotencycache "bluebell/internal/infrastructure/persistence/redis/idempotency"
 	postcache "bluebell/internal/infrastructure/persistence/redis/post"
 	usercache "bluebell/internal/infrastructure/persistence/redis/user"
 	"bluebell/internal/domain"
@@ -13,7 +14,11 @@ import (
 type Repositories struct {
 	PostCache         domain.PostCacheRepository
 	TokenCache        domain.UserTokenCacheRepository
+	IdempotencyCache  domain.IdempotencyCacheRepository
 	HotScoreRefresher *postcache.HotScoreRefresher
+
+	// PostFilter 帖子 ID 布隆过滤器，容量和误判率来自配置，由调用方创建后设置；为 nil 时不做过滤
+	PostFilter domain.PostFilter
 }
 
 // NewRepositories 创建缓存仓储聚合实例
@@ -22,6 +27,7 @@ func NewRepositories(rdb *redis.Client) *Repositories {
 	return &Repositories{
 		PostCache:         postCache,
 		TokenCache:        usercache.NewUserTokenCache(rdb),
+		IdempotencyCache:  idempotencycache.NewIdempotencyCache(rdb),
 		HotScoreRefresher: refresher,
 	}
 }
@@ -0,0 +1,90 @@
+package idempotencycache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Keys 相关常量
+const (
+	keyPrefix      = "bluebell:"
+	keyIdempotency = "idempotency:" // bluebell:idempotency:{userID}:{method}:{path}:{Idempotency-Key}
+)
+
+func getRedisKey(key string) string {
+	return keyPrefix + keyIdempotency + key
+}
+
+// idempotencyCacheStruct 幂等键缓存仓储实现
+type idempotencyCacheStruct struct {
+	rdb *redis.Client
+}
+
+// NewIdempotencyCache 创建 idempotencyCacheStruct 实例
+func NewIdempotencyCache(rdb *redis.Client) domain.IdempotencyCacheRepository {
+	return &idempotencyCacheStruct{rdb: rdb}
+}
+
+// Acquire 使用 SETNX 写入 processing 占位记录，抢占幂等键的处理权
+func (c *idempotencyCacheStruct) Acquire(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (bool, error) {
+	val, err := json.Marshal(&entity.IdempotencyRecord{
+		State:       entity.IdempotencyProcessing,
+		Fingerprint: fingerprint,
+	})
+	if err != nil {
+		return false, fmt.Errorf("marshal idempotency record failed: %w", err)
+	}
+
+	ok, err := c.rdb.SetNX(ctx, getRedisKey(key), val, lockTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("idempotency acquire failed (key: %s): %w", key, err)
+	}
+	return ok, nil
+}
+
+// Get 获取幂等记录，不存在时返回 nil
+func (c *idempotencyCacheStruct) Get(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	val, err := c.rdb.Get(ctx, getRedisKey(key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("idempotency get failed (key: %s): %w", key, err)
+	}
+
+	record := new(entity.IdempotencyRecord)
+	if err := json.Unmarshal(val, record); err != nil {
+		return nil, fmt.Errorf("unmarshal idempotency record failed (key: %s): %w", key, err)
+	}
+	return record, nil
+}
+
+// Complete 覆盖占位记录，保存首次请求的状态码和响应体
+func (c *idempotencyCacheStruct) Complete(ctx context.Context, key string, record *entity.IdempotencyRecord, ttl time.Duration) error {
+	record.State = entity.IdempotencyCompleted
+	val, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency record failed: %w", err)
+	}
+
+	if err := c.rdb.Set(ctx, getRedisKey(key), val, ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency complete failed (key: %s): %w", key, err)
+	}
+	return nil
+}
+
+// Release 删除占位记录，允许客户端使用同一个幂等键重试
+func (c *idempotencyCacheStruct) Release(ctx context.Context, key string) error {
+	if err := c.rdb.Del(ctx, getRedisKey(key)).Err(); err != nil {
+		return fmt.Errorf("idempotency release failed (key: %s): %w", key, err)
+	}
+	return nil
+}
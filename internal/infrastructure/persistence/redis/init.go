@@ -2,11 +2,13 @@ package cache
 
 import (
 	"bluebell/internal/config"
+	"bluebell/internal/infrastructure/metrics"
 	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
@@ -25,8 +27,15 @@ func Init(cfg *config.Config) (*redis.Client, error) {
 		PoolSize: redisCfg.PoolSize,
 	})
 
-	// 启用 OpenTelemetry 追踪插件
-	// 启用 OpenTelemetry 指标插件
+	// 启用 OpenTelemetry 追踪插件，每条命令（Pipeline 整体）创建一个子 Span
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		return nil, fmt.Errorf("instrument redis tracing failed: %w", err)
+	}
+
+	// 连接池指标在采集时读取 PoolStats
+	if err := metrics.RegisterRedisPoolStats(rdb); err != nil {
+		zap.L().Warn("register redis pool metrics failed", zap.Error(err))
+	}
 
 	pingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,299 @@
+package postcache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/metrics"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ========== 帖子 ID 布隆过滤器 ==========
+
+const (
+	keyPostBloomPrefix  = "post:bloom:"     // bluebell:post:bloom:{m}:{k} - 位图，参数变化后自动换用新键并重建
+	keyPostBloomLock    = "post:bloom:lock" // 重建互斥锁，多副本同时启动时只有一个执行重建
+	bloomBuildingSuffix = ":building"       // 重建中的临时位图，完成后 RENAME 为正式键
+	bloomLockTTL        = 30 * time.Minute  // 重建锁的最长持有时间，进程崩溃后锁自动释放
+	bloomRepairInterval = time.Minute       // 检查过滤器是否需要重建的间隔
+	bloomCatchUpGrace   = time.Minute       // 重建完成后补录重建开始前这段时间内写入的帖子，覆盖扫描时尚未提交的事务
+)
+
+// bloomMightExistScript 位图不存在（尚未构建）时返回 -1，任一位为 0 时返回 0，否则返回 1
+var bloomMightExistScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+for _, offset in ipairs(ARGV) do
+	if redis.call('GETBIT', KEYS[1], offset) == 0 then
+		return 0
+	end
+end
+return 1
+`)
+
+// bloomAddScript 只写入已存在的位图：正式位图尚未构建时由重建负责收录，
+// 重建进行中时同时写入临时位图，避免重建扫描之后新建的帖子在切换后丢失
+var bloomAddScript = redis.NewScript(`
+for i = 1, #KEYS do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		for _, offset in ipairs(ARGV) do
+			redis.call('SETBIT', KEYS[i], offset, 1)
+		end
+	end
+end
+return 0
+`)
+
+// BloomOptions 布隆过滤器参数
+type BloomOptions struct {
+	ExpectedItems     int64   // 预计帖子总数，超出后误判率上升
+	FalsePositiveRate float64 // 目标误判率
+	RebuildBatchSize  int     // 重建时每批从 MySQL 读取的帖子数
+}
+
+// BloomFilter 基于 Redis 位图的帖子 ID 布隆过滤器，实现 domain.PostFilter
+// 只增不删：删除、隐藏的帖子仍被视为“可能存在”，由后续查询处理，不影响正确性
+type BloomFilter struct {
+	rdb       *redis.Client
+	bits      uint64 // 位图长度 m
+	hashes    int    // 哈希函数个数 k
+	batchSize int
+	key       string
+
+	// invalidatePending 写入失败后未能删除共享位图（Redis 不可用）时置位：
+	// 本进程在此期间放行所有查询，并由 RepairLoop 重试删除
+	invalidatePending atomic.Bool
+}
+
+// NewBloomFilter 按预计容量和误判率计算位图长度和哈希个数，创建布隆过滤器
+func NewBloomFilter(rdb *redis.Client, opts BloomOptions) *BloomFilter {
+	n, p := float64(opts.ExpectedItems), opts.FalsePositiveRate
+	m := uint64(math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := max(int(math.Round(float64(m)/n*math.Ln2)), 1)
+	return &BloomFilter{
+		rdb:       rdb,
+		bits:      m,
+		hashes:    k,
+		batchSize: opts.RebuildBatchSize,
+		key:       redisKey(fmt.Sprintf("%s%d:%d", keyPostBloomPrefix, m, k)),
+	}
+}
+
+// offsets 双重哈希（Kirsch-Mitzenmacher）计算 k 个位偏移，各进程对同一 ID 结果一致
+func (f *BloomFilter) offsets(postID int64) []int64 {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(postID))
+	h := fnv.New64a()
+	h.Write(buf[:])
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+
+	offsets := make([]int64, f.hashes)
+	for i := range offsets {
+		offsets[i] = int64((h1 + uint64(i)*h2) % f.bits)
+	}
+	return offsets
+}
+
+// scriptArgs 位偏移转换为 Lua 脚本参数
+func (f *BloomFilter) scriptArgs(postID int64) []interface{} {
+	offsets := f.offsets(postID)
+	args := make([]interface{}, len(offsets))
+	for i, o := range offsets {
+		args[i] = o
+	}
+	return args
+}
+
+// MightExist 帖子 ID 可能存在时返回 true，返回 false 时一定不存在
+// 过滤器尚未构建（如首次部署、参数变更）时返回 true，所有请求照常查库
+func (f *BloomFilter) MightExist(ctx context.Context, postID int64) (bool, error) {
+	if f.invalidatePending.Load() {
+		return true, nil
+	}
+	res, err := bloomMightExistScript.Run(ctx, f.rdb, []string{f.key}, f.scriptArgs(postID)...).Int()
+	if err != nil {
+		return true, fmt.Errorf("bloom filter lookup failed (post_id: %d): %w", postID, err)
+	}
+	if res == 0 {
+		metrics.PostFilterRejections.Inc()
+		return false, nil
+	}
+	return true, nil
+}
+
+// Add 记录新建的帖子 ID
+// 写入失败时删除共享位图，使所有副本的查询都放行，再由任一副本的 RepairLoop 重建；
+// 删除也失败时本进程先行放行，并在 RepairLoop 中重试删除
+func (f *BloomFilter) Add(ctx context.Context, postID int64) error {
+	keys := []string{f.key, f.key + bloomBuildingSuffix}
+	if err := bloomAddScript.Run(ctx, f.rdb, keys, f.scriptArgs(postID)...).Err(); err != nil {
+		metrics.PostFilterAddFailures.Inc()
+		if invErr := f.invalidate(context.WithoutCancel(ctx)); invErr != nil {
+			f.invalidatePending.Store(true)
+			err = errors.Join(err, invErr)
+		}
+		return fmt.Errorf("bloom filter add failed (post_id: %d): %w", postID, err)
+	}
+	return nil
+}
+
+// invalidate 删除正式位图，之后所有副本的 MightExist 都返回“可能存在”，直到重建完成
+// 不删除重建中的临时位图：重建完成后的补录会收录这段时间内新建的帖子
+func (f *BloomFilter) invalidate(ctx context.Context) error {
+	if err := f.rdb.Del(ctx, f.key).Err(); err != nil {
+		return fmt.Errorf("invalidate bloom filter failed: %w", err)
+	}
+	return nil
+}
+
+// Built 判断过滤器是否已构建
+func (f *BloomFilter) Built(ctx context.Context) (bool, error) {
+	n, err := f.rdb.Exists(ctx, f.key).Result()
+	if err != nil {
+		return false, fmt.Errorf("check bloom filter failed: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ErrBloomRebuildInProgress 其他进程正在重建过滤器
+var ErrBloomRebuildInProgress = errors.New("bloom filter rebuild already in progress")
+
+// Rebuild 从 MySQL 全量重建过滤器，返回收录的帖子数
+// 先写入临时位图再原子替换正式位图，重建期间查询照常使用旧位图（或在首次构建前放行）。
+// 替换后补录重建开始前 bloomCatchUpGrace 以来修改过的帖子：写入过滤器失败的帖子若在扫描经过后才提交，
+// 既不在临时位图中也没有被 Add 写入，补录保证它不会被永久拦截
+func (f *BloomFilter) Rebuild(ctx context.Context, posts domain.PostRepository) (int, error) {
+	ok, err := f.rdb.SetNX(ctx, redisKey(keyPostBloomLock), f.key, bloomLockTTL).Result()
+	if err != nil {
+		return 0, fmt.Errorf("acquire bloom rebuild lock failed: %w", err)
+	}
+	if !ok {
+		return 0, ErrBloomRebuildInProgress
+	}
+	defer f.rdb.Del(context.WithoutCancel(ctx), redisKey(keyPostBloomLock))
+
+	building := f.key + bloomBuildingSuffix
+	// 预先分配完整位图，使 Add 在重建期间能看到临时位图并同步写入
+	if _, err := f.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, building)
+		pipe.SetBit(ctx, building, int64(f.bits-1), 0)
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("init bloom rebuild failed: %w", err)
+	}
+
+	startedAt := time.Now()
+	total := 0
+	err = posts.ScanPosts(ctx, time.Time{}, f.batchSize, func(batch []*entity.Post) error {
+		_, err := f.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, p := range batch {
+				id, err := strconv.ParseInt(p.PostID, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid post id %q: %w", p.PostID, err)
+				}
+				for _, offset := range f.offsets(id) {
+					pipe.SetBit(ctx, building, offset, 1)
+				}
+			}
+			return nil
+		})
+		total += len(batch)
+		return err
+	})
+	if err != nil {
+		f.rdb.Del(context.WithoutCancel(ctx), building)
+		return total, fmt.Errorf("rebuild bloom filter failed: %w", err)
+	}
+
+	if err := f.rdb.Rename(ctx, building, f.key).Err(); err != nil {
+		return total, fmt.Errorf("swap bloom filter failed: %w", err)
+	}
+
+	if err := f.catchUp(ctx, posts, startedAt.Add(-bloomCatchUpGrace)); err != nil {
+		// 补录失败时无法确认位图完整，删除后等待下一次重建
+		_ = f.invalidate(context.WithoutCancel(ctx))
+		return total, err
+	}
+	return total, nil
+}
+
+// catchUp 把 since 之后修改过的帖子写入正式位图；位图不存在（已被删除等待重建）时跳过
+func (f *BloomFilter) catchUp(ctx context.Context, posts domain.PostRepository, since time.Time) error {
+	err := posts.ScanPosts(ctx, since, f.batchSize, func(batch []*entity.Post) error {
+		for _, p := range batch {
+			id, err := strconv.ParseInt(p.PostID, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid post id %q: %w", p.PostID, err)
+			}
+			if err := bloomAddScript.Run(ctx, f.rdb, []string{f.key}, f.scriptArgs(id)...).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("catch up bloom filter failed: %w", err)
+	}
+	return nil
+}
+
+// EnsureBuilt 过滤器尚未构建时从 MySQL 重建，其他进程正在重建时直接返回
+// 首次部署、参数变更、写入失败后位图被删除、Redis 数据丢失时都会触发，构建完成前查询全部放行
+func (f *BloomFilter) EnsureBuilt(ctx context.Context, posts domain.PostRepository) (rebuilt int, err error) {
+	built, err := f.Built(ctx)
+	if err != nil || built {
+		return 0, err
+	}
+	rebuilt, err = f.Rebuild(ctx, posts)
+	if errors.Is(err, ErrBloomRebuildInProgress) {
+		return 0, nil
+	}
+	return rebuilt, err
+}
+
+// RepairLoop 启动时立即、之后定期确保过滤器已构建，阻塞直到 ctx 取消
+// 每个副本都运行：位图因写入失败被删除后，由最先检查到的副本重建
+func (f *BloomFilter) RepairLoop(ctx context.Context, posts domain.PostRepository) {
+	ticker := time.NewTicker(bloomRepairInterval)
+	defer ticker.Stop()
+	for {
+		f.repair(ctx, posts)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// repair 重试未完成的删除，再在位图缺失时重建
+func (f *BloomFilter) repair(ctx context.Context, posts domain.PostRepository) {
+	if f.invalidatePending.Load() {
+		if err := f.invalidate(ctx); err != nil {
+			zap.L().Error("invalidate post bloom filter failed", zap.Error(err))
+			return
+		}
+		f.invalidatePending.Store(false)
+	}
+	n, err := f.EnsureBuilt(ctx, posts)
+	if err != nil {
+		zap.L().Error("build post bloom filter failed", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		zap.L().Info("post bloom filter built", zap.Int("posts", n))
+	}
+}
@@ -0,0 +1,143 @@
+package postcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// scanRepo 只实现 ScanPosts 的帖子仓储
+// all 为全量扫描看到的帖子；late 为全量扫描经过后才提交的帖子，只有按时间增量扫描时可见
+type scanRepo struct {
+	domain.PostRepository
+	all, late []int64
+}
+
+func (r *scanRepo) ScanPosts(_ context.Context, updatedSince time.Time, _ int, fn func([]*entity.Post) error) error {
+	ids := r.all
+	if !updatedSince.IsZero() {
+		ids = append(append([]int64(nil), r.all...), r.late...)
+	}
+	posts := make([]*entity.Post, 0, len(ids))
+	for _, id := range ids {
+		posts = append(posts, &entity.Post{PostID: strconv.FormatInt(id, 10)})
+	}
+	return fn(posts)
+}
+
+// failScripts 让 Lua 脚本命令失败，模拟写入过滤器失败而其他命令正常
+type failScripts struct{}
+
+func (failScripts) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (failScripts) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if strings.HasPrefix(cmd.Name(), "eval") {
+			cmd.SetErr(errors.New("script failed"))
+			return cmd.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (failScripts) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func newTestFilter(t *testing.T, mr *miniredis.Miniredis) (*BloomFilter, *redis.Client) {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewBloomFilter(rdb, BloomOptions{ExpectedItems: 1000, FalsePositiveRate: 0.001, RebuildBatchSize: 100}), rdb
+}
+
+func mightExist(t *testing.T, f *BloomFilter, id int64) bool {
+	t.Helper()
+	ok, err := f.MightExist(context.Background(), id)
+	if err != nil {
+		t.Fatalf("MightExist(%d) error = %v", id, err)
+	}
+	return ok
+}
+
+func TestBloomFilter_AddFailureVisibleToOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	a, rdbA := newTestFilter(t, mr)
+	b, _ := newTestFilter(t, mr)
+	repo := &scanRepo{all: []int64{1}}
+
+	if _, err := a.EnsureBuilt(ctx, repo); err != nil {
+		t.Fatalf("EnsureBuilt() error = %v", err)
+	}
+	if !mightExist(t, b, 1) || mightExist(t, b, 2) {
+		t.Fatal("replica b should see post 1 only")
+	}
+
+	// 副本 a 写入失败：共享位图被删除，副本 b 立即放行新帖子
+	rdbA.AddHook(failScripts{})
+	if err := a.Add(ctx, 2); err == nil {
+		t.Fatal("Add() should fail")
+	}
+	repo.all = append(repo.all, 2)
+	if !mightExist(t, b, 2) {
+		t.Error("replica b rejects post 2 after a failed Add on replica a")
+	}
+
+	// 任一副本检查时重建，重建后新帖子被收录
+	b.repair(ctx, repo)
+	if !mightExist(t, b, 2) || mightExist(t, b, 3) {
+		t.Error("rebuilt filter should contain post 2 and still reject post 3")
+	}
+}
+
+func TestBloomFilter_InvalidateRetriedWhenRedisDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	a, _ := newTestFilter(t, mr)
+	b, _ := newTestFilter(t, mr)
+	repo := &scanRepo{all: []int64{1}}
+	if _, err := a.EnsureBuilt(ctx, repo); err != nil {
+		t.Fatalf("EnsureBuilt() error = %v", err)
+	}
+
+	// Redis 不可用：写入和删除都失败，本进程先行放行
+	mr.SetError("down")
+	if err := a.Add(ctx, 2); err == nil {
+		t.Fatal("Add() should fail while Redis is down")
+	}
+	if ok, _ := a.MightExist(ctx, 2); !ok {
+		t.Error("replica a should let post 2 through while the failure is unrecorded")
+	}
+	mr.SetError("")
+	repo.all = append(repo.all, 2)
+
+	// Redis 恢复后 a 补上删除并重建，其他副本随之可见
+	a.repair(ctx, repo)
+	if !mightExist(t, b, 2) {
+		t.Error("replica b rejects post 2 after replica a repaired the filter")
+	}
+}
+
+func TestBloomFilter_RebuildCatchesUpLateCommits(t *testing.T) {
+	mr := miniredis.RunT(t)
+	f, _ := newTestFilter(t, mr)
+	// 帖子 2 写入过滤器失败，且事务在全量扫描经过它之后才提交
+	repo := &scanRepo{all: []int64{1}, late: []int64{2}}
+
+	if _, err := f.Rebuild(context.Background(), repo); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+	if !mightExist(t, f, 2) {
+		t.Error("post committed during the rebuild scan is filtered out")
+	}
+}
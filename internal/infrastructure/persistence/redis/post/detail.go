@@ -0,0 +1,381 @@
+package postcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/domain/event"
+	"bluebell/internal/infrastructure/logger"
+	"bluebell/internal/infrastructure/metrics"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// ========== 帖子详情缓存（Cache-Aside） ==========
+
+const (
+	keyPostDetailPrefix = "post:detail:" // bluebell:post:detail:{postID} - 帖子详情 JSON（含作者、社区）
+
+	// detailTombstone 不存在（或不可见）的帖子的占位值，防止无效 ID 反复穿透到 MySQL
+	detailTombstone = "-"
+)
+
+// DetailOptions 帖子详情缓存参数
+type DetailOptions struct {
+	TTL         time.Duration // 详情缓存的基础过期时间
+	Jitter      time.Duration // 在 TTL 上叠加 [0, Jitter) 的随机时长，避免同一批写入的缓存同时过期
+	NegativeTTL time.Duration // 不存在的帖子占位值的过期时间
+}
+
+// cachedPost 帖子详情的缓存格式
+// 单独定义而不直接序列化 entity，避免实体字段变动影响已有缓存，也避免把作者密码等字段写入 Redis
+type cachedPost struct {
+	PostID      string    `json:"post_id"`
+	AuthorID    int64     `json:"author_id"`
+	CommunityID int64     `json:"community_id"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	Status      int8      `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 预加载的作者和社区，Has* 为 false 表示查询时未关联到
+	HasAuthor  bool   `json:"has_author,omitempty"`
+	AuthorName string `json:"author_name,omitempty"`
+	AuthorRole int    `json:"author_role,omitempty"`
+
+	HasCommunity          bool   `json:"has_community,omitempty"`
+	CommunityName         string `json:"community_name,omitempty"`
+	CommunityIntroduction string `json:"community_introduction,omitempty"`
+}
+
+func toCachedPost(p *entity.Post) *cachedPost {
+	c := &cachedPost{
+		PostID:      p.PostID,
+		AuthorID:    p.AuthorID,
+		CommunityID: p.CommunityID,
+		Title:       p.PostTitle,
+		Content:     p.Content,
+		Status:      p.Status,
+		CreatedAt:   p.CreatedAt,
+	}
+	if p.Author != nil {
+		c.HasAuthor = true
+		c.AuthorName = p.Author.UserName
+		c.AuthorRole = p.Author.Role
+	}
+	if p.Community != nil {
+		c.HasCommunity = true
+		c.CommunityName = p.Community.CommunityName
+		c.CommunityIntroduction = p.Community.Introduction
+	}
+	return c
+}
+
+func (c *cachedPost) toEntity() *entity.Post {
+	p := &entity.Post{
+		PostID:      c.PostID,
+		AuthorID:    c.AuthorID,
+		CommunityID: c.CommunityID,
+		PostTitle:   c.Title,
+		Content:     c.Content,
+		Status:      c.Status,
+		CreatedAt:   c.CreatedAt,
+	}
+	if c.HasAuthor {
+		p.Author = &entity.User{UserID: c.AuthorID, UserName: c.AuthorName, Role: c.AuthorRole}
+	}
+	if c.HasCommunity {
+		p.Community = &entity.Community{ID: c.CommunityID, CommunityName: c.CommunityName, Introduction: c.CommunityIntroduction}
+	}
+	return p
+}
+
+func detailKey(postID string) string {
+	return redisKey(keyPostDetailPrefix + postID)
+}
+
+// CachedPostRepository 带详情缓存的帖子仓储，包装 MySQL 实现
+// 读：先批量 MGET，未命中的 ID 合并为一次 MySQL 查询回源并写回缓存，并发请求中正在回源的 ID 不再重复查询；
+// 写：删除类操作在写库后删除缓存，帖子编辑、隐藏、恢复、删除事件也会触发失效（见 RegisterInvalidation）
+type CachedPostRepository struct {
+	domain.PostRepository // 未覆盖的方法（ScanPosts）直接委托给被包装的仓储
+
+	rdb  *redis.Client
+	opts DetailOptions
+
+	mu       sync.Mutex
+	inflight map[string]*detailCall // 正在回源的帖子 ID
+}
+
+// detailCall 单个帖子 ID 的一次回源，同时请求该 ID 的调用方共享结果
+type detailCall struct {
+	done chan struct{}
+	post *entity.Post // 帖子不存在时为 nil
+	err  error
+}
+
+// NewCachedPostRepository 创建带详情缓存的帖子仓储
+func NewCachedPostRepository(rdb *redis.Client, next domain.PostRepository, opts DetailOptions) *CachedPostRepository {
+	return &CachedPostRepository{
+		PostRepository: next,
+		rdb:            rdb,
+		opts:           opts,
+		inflight:       make(map[string]*detailCall),
+	}
+}
+
+// GetPostByID 根据帖子 ID 获取帖子详情，帖子不存在时返回 nil, nil（与 MySQL 实现一致）
+func (r *CachedPostRepository) GetPostByID(ctx context.Context, pid int64) (*entity.Post, error) {
+	posts, err := r.GetPostListByIDsWithPreload(ctx, []string{strconv.FormatInt(pid, 10)})
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, nil
+	}
+	return posts[0], nil
+}
+
+// GetPostListByIDsWithPreload 按传入顺序批量获取帖子详情，跳过不存在的帖子
+func (r *CachedPostRepository) GetPostListByIDsWithPreload(ctx context.Context, ids []string) ([]*entity.Post, error) {
+	if len(ids) == 0 {
+		return make([]*entity.Post, 0), nil
+	}
+
+	found, missing := r.lookup(ctx, ids)
+	if len(missing) > 0 {
+		loaded, err := r.load(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for id, p := range loaded {
+			found[id] = p
+		}
+	}
+
+	posts := make([]*entity.Post, 0, len(ids))
+	for _, id := range ids {
+		if p := found[id]; p != nil {
+			posts = append(posts, p)
+		}
+	}
+	return posts, nil
+}
+
+// lookup 批量读取缓存，返回命中的帖子（占位值命中时对应项为 nil）和未命中的 ID
+// Redis 出错时整体视为未命中，降级为直接查库
+func (r *CachedPostRepository) lookup(ctx context.Context, ids []string) (map[string]*entity.Post, []string) {
+	found := make(map[string]*entity.Post, len(ids))
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = detailKey(id)
+	}
+	vals, err := r.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		logger.WithContext(ctx).Warn("post detail cache MGET failed, falling back to MySQL", zap.Error(err))
+		metrics.PostDetailCacheLookups.WithLabelValues("error").Add(float64(len(ids)))
+		return found, ids
+	}
+
+	var missing []string
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			missing = append(missing, ids[i])
+			metrics.PostDetailCacheLookups.WithLabelValues("miss").Inc()
+			continue
+		}
+		if s == detailTombstone {
+			found[ids[i]] = nil
+			metrics.PostDetailCacheLookups.WithLabelValues("negative").Inc()
+			continue
+		}
+		var c cachedPost
+		if err := json.Unmarshal([]byte(s), &c); err != nil {
+			// 格式不兼容的旧缓存按未命中处理，回源后会被覆盖
+			missing = append(missing, ids[i])
+			metrics.PostDetailCacheLookups.WithLabelValues("miss").Inc()
+			continue
+		}
+		found[ids[i]] = c.toEntity()
+		metrics.PostDetailCacheLookups.WithLabelValues("hit").Inc()
+	}
+	return found, missing
+}
+
+// load 回源 MySQL 并写回缓存，返回存在的帖子
+// 按 ID 合并并发回源：其他请求正在回源的 ID 等待其结果，其余 ID 合并为一次批量查询，
+// 因此 ID 集合部分重叠的并发请求也不会重复查询同一帖子
+func (r *CachedPostRepository) load(ctx context.Context, ids []string) (map[string]*entity.Post, error) {
+	calls := make(map[string]*detailCall, len(ids))
+	own := make([]string, 0, len(ids))
+	r.mu.Lock()
+	for _, id := range ids {
+		if c, ok := r.inflight[id]; ok {
+			calls[id] = c
+			continue
+		}
+		c := &detailCall{done: make(chan struct{})}
+		r.inflight[id] = c
+		calls[id] = c
+		own = append(own, id)
+	}
+	r.mu.Unlock()
+
+	if len(own) > 0 {
+		r.fetch(ctx, own, calls)
+	}
+
+	loaded := make(map[string]*entity.Post, len(ids))
+	for _, id := range ids {
+		c := calls[id]
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if c.err != nil {
+			return nil, c.err
+		}
+		if c.post != nil {
+			loaded[id] = c.post
+		}
+	}
+	return loaded, nil
+}
+
+// fetch 批量查询本请求负责回源的 ids 并写回缓存，完成后唤醒等待这些 ID 的请求
+// 回源不受调用方取消的影响，避免首个请求超时导致等待同一 ID 的请求全部失败
+func (r *CachedPostRepository) fetch(ctx context.Context, ids []string, calls map[string]*detailCall) {
+	loadCtx := context.WithoutCancel(ctx)
+	posts, err := r.PostRepository.GetPostListByIDsWithPreload(loadCtx, ids)
+	loaded := make(map[string]*entity.Post, len(posts))
+	if err == nil {
+		for _, p := range posts {
+			loaded[p.PostID] = p
+		}
+		r.backfill(loadCtx, ids, loaded)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		c := calls[id]
+		c.post, c.err = loaded[id], err
+		delete(r.inflight, id)
+		close(c.done)
+	}
+}
+
+// backfill 写回缓存：存在的帖子写入详情，不存在的写入占位值；写回失败只影响命中率，不返回错误
+func (r *CachedPostRepository) backfill(ctx context.Context, ids []string, loaded map[string]*entity.Post) {
+	_, err := r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			p, ok := loaded[id]
+			if !ok {
+				pipe.Set(ctx, detailKey(id), detailTombstone, r.opts.NegativeTTL)
+				continue
+			}
+			data, err := json.Marshal(toCachedPost(p))
+			if err != nil {
+				return fmt.Errorf("marshal post %s: %w", id, err)
+			}
+			pipe.Set(ctx, detailKey(id), data, r.ttl())
+		}
+		return nil
+	})
+	if err != nil {
+		logger.WithContext(ctx).Warn("post detail cache backfill failed", zap.Int("count", len(ids)), zap.Error(err))
+	}
+}
+
+// ttl 基础 TTL 加随机抖动
+func (r *CachedPostRepository) ttl() time.Duration {
+	if r.opts.Jitter <= 0 {
+		return r.opts.TTL
+	}
+	return r.opts.TTL + rand.N(r.opts.Jitter)
+}
+
+// CreatePost 写库后清除可能存在的占位值，使新帖子立即可见
+func (r *CachedPostRepository) CreatePost(ctx context.Context, post *entity.Post) error {
+	if err := r.PostRepository.CreatePost(ctx, post); err != nil {
+		return err
+	}
+	r.invalidate(ctx, post.PostID)
+	return nil
+}
+
+// DeletePostByAuthor 写库后删除详情缓存
+func (r *CachedPostRepository) DeletePostByAuthor(ctx context.Context, postID, authorID int64) error {
+	if err := r.PostRepository.DeletePostByAuthor(ctx, postID, authorID); err != nil {
+		return err
+	}
+	r.invalidate(ctx, strconv.FormatInt(postID, 10))
+	return nil
+}
+
+// Invalidate 删除指定帖子的详情缓存
+func (r *CachedPostRepository) Invalidate(ctx context.Context, postIDs ...int64) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, len(postIDs))
+	for i, id := range postIDs {
+		keys[i] = detailKey(strconv.FormatInt(id, 10))
+	}
+	if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("invalidate post detail cache failed: %w", err)
+	}
+	return nil
+}
+
+// invalidate 删除单个帖子的详情缓存，失败只记录日志：缓存最迟在 TTL 到期后恢复一致
+func (r *CachedPostRepository) invalidate(ctx context.Context, postID string) {
+	if err := r.rdb.Del(ctx, detailKey(postID)).Err(); err != nil {
+		logger.WithContext(ctx).Warn("post detail cache invalidation failed",
+			zap.String("post_id", postID), zap.Error(err))
+	}
+}
+
+// RegisterInvalidation 订阅帖子生命周期事件，帖子内容或可见性变化时删除详情缓存
+// 事件可能在事务提交前分发，提交前有并发读取回填旧数据的窗口；
+// 删除帖子的主流程在提交后还会经 PostCacheRepository.DeletePost 再清理一次
+func (r *CachedPostRepository) RegisterInvalidation(bus domain.EventBus) {
+	for _, name := range []string{
+		event.NamePostEdited, event.NamePostHidden, event.NamePostRestored, event.NamePostDeleted,
+	} {
+		bus.Subscribe(name, r.onPostChanged)
+	}
+}
+
+func (r *CachedPostRepository) onPostChanged(ctx context.Context, e event.Event) error {
+	var postID int64
+	switch ev := e.(type) {
+	case event.PostEdited:
+		postID = ev.PostID
+	case event.PostHidden:
+		postID = ev.PostID
+	case event.PostRestored:
+		postID = ev.PostID
+	case event.PostDeleted:
+		postID = ev.PostID
+	default:
+		return fmt.Errorf("unexpected post event %s", e.EventName())
+	}
+	// 缓存失效失败不应回滚业务事务，只记录日志
+	if err := r.Invalidate(ctx, postID); err != nil {
+		logger.WithContext(ctx).Warn("invalidate post detail cache on event failed",
+			zap.String("event", e.EventName()), zap.Int64("post_id", postID), zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package postcache
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// detailRepo 只实现批量查询的帖子仓储，记录每次回源的 ID
+type detailRepo struct {
+	domain.PostRepository
+
+	posts map[string]*entity.Post
+	// block 非空时第一次查询在返回前等待其关闭，entered 在进入该查询时关闭
+	block, entered chan struct{}
+
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (r *detailRepo) GetPostListByIDsWithPreload(_ context.Context, ids []string) ([]*entity.Post, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, append([]string(nil), ids...))
+	first := len(r.calls) == 1
+	r.mu.Unlock()
+
+	if first && r.block != nil {
+		close(r.entered)
+		<-r.block
+	}
+	posts := make([]*entity.Post, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := r.posts[id]; ok {
+			posts = append(posts, p)
+		}
+	}
+	return posts, nil
+}
+
+func (r *detailRepo) queried() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+func newTestDetailCache(t *testing.T, repo *detailRepo) (*CachedPostRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewCachedPostRepository(rdb, repo, DetailOptions{TTL: time.Hour, NegativeTTL: time.Minute}), mr
+}
+
+func postIDs(posts []*entity.Post) []string {
+	ids := make([]string, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.PostID)
+	}
+	return ids
+}
+
+func TestCachedPostRepository_HitsMissesAndBackfill(t *testing.T) {
+	ctx := context.Background()
+	repo := &detailRepo{posts: map[string]*entity.Post{
+		"1": {PostID: "1", PostTitle: "from mysql"},
+		"2": {PostID: "2", PostTitle: "second", Author: &entity.User{UserID: 7, UserName: "alice"}},
+	}}
+	c, mr := newTestDetailCache(t, repo)
+
+	// 帖子 1 已缓存（标题与库中不同，用于确认命中缓存），帖子 3 已有占位值
+	cached, _ := json.Marshal(toCachedPost(&entity.Post{PostID: "1", PostTitle: "from cache"}))
+	mr.Set(detailKey("1"), string(cached))
+	mr.Set(detailKey("3"), detailTombstone)
+
+	posts, err := c.GetPostListByIDsWithPreload(ctx, []string{"3", "2", "4", "1"})
+	if err != nil {
+		t.Fatalf("GetPostListByIDsWithPreload() error = %v", err)
+	}
+	if got := postIDs(posts); !slices.Equal(got, []string{"2", "1"}) {
+		t.Fatalf("posts = %v, want [2 1] in request order", got)
+	}
+	if posts[1].PostTitle != "from cache" {
+		t.Errorf("post 1 title = %q, want the cached value", posts[1].PostTitle)
+	}
+	if posts[0].Author == nil || posts[0].Author.UserName != "alice" {
+		t.Errorf("post 2 author = %+v, want preloaded author", posts[0].Author)
+	}
+	// 只有未命中的 2、4 回源，占位值不穿透
+	if got := repo.queried(); len(got) != 1 || !slices.Equal(got[0], []string{"2", "4"}) {
+		t.Fatalf("MySQL queries = %v, want [[2 4]]", got)
+	}
+
+	// 回源结果写回：存在的帖子写入详情，不存在的写入占位值
+	if v, _ := mr.Get(detailKey("4")); v != detailTombstone {
+		t.Errorf("post 4 cache = %q, want tombstone", v)
+	}
+	if ttl := mr.TTL(detailKey("4")); ttl != time.Minute {
+		t.Errorf("tombstone TTL = %v, want %v", ttl, time.Minute)
+	}
+	if ttl := mr.TTL(detailKey("2")); ttl != time.Hour {
+		t.Errorf("detail TTL = %v, want %v", ttl, time.Hour)
+	}
+
+	// 再次读取全部命中缓存
+	posts, err = c.GetPostListByIDsWithPreload(ctx, []string{"2", "4"})
+	if err != nil {
+		t.Fatalf("GetPostListByIDsWithPreload() error = %v", err)
+	}
+	if got := postIDs(posts); !slices.Equal(got, []string{"2"}) {
+		t.Errorf("posts = %v, want [2]", got)
+	}
+	if posts[0].Author == nil || posts[0].Author.UserName != "alice" {
+		t.Errorf("cached post 2 author = %+v, want alice", posts[0].Author)
+	}
+	if got := repo.queried(); len(got) != 1 {
+		t.Errorf("MySQL queries = %v, want no further queries", got)
+	}
+}
+
+func TestCachedPostRepository_OverlappingLoadsShareInflightIDs(t *testing.T) {
+	ctx := context.Background()
+	repo := &detailRepo{
+		posts: map[string]*entity.Post{
+			"1": {PostID: "1"}, "2": {PostID: "2"}, "3": {PostID: "3"},
+		},
+		block:   make(chan struct{}),
+		entered: make(chan struct{}),
+	}
+	c, _ := newTestDetailCache(t, repo)
+
+	type result struct {
+		posts []*entity.Post
+		err   error
+	}
+	first := make(chan result, 1)
+	go func() {
+		posts, err := c.GetPostListByIDsWithPreload(ctx, []string{"1", "2"})
+		first <- result{posts, err}
+	}()
+	<-repo.entered
+
+	// 帖子 2 正在被第一个请求回源，第二个请求只查询帖子 3，并等待帖子 2 的结果
+	second := make(chan result, 1)
+	go func() {
+		posts, err := c.GetPostListByIDsWithPreload(ctx, []string{"2", "3"})
+		second <- result{posts, err}
+	}()
+	deadline := time.Now().Add(time.Second)
+	for len(repo.queried()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-second:
+		t.Fatal("second load returned before the in-flight post 2 was loaded")
+	default:
+	}
+	close(repo.block)
+
+	for name, ch := range map[string]chan result{"first": first, "second": second} {
+		r := <-ch
+		if r.err != nil {
+			t.Fatalf("%s load error = %v", name, r.err)
+		}
+		if len(r.posts) != 2 {
+			t.Errorf("%s load posts = %v, want 2 posts", name, postIDs(r.posts))
+		}
+	}
+	want := [][]string{{"1", "2"}, {"3"}}
+	if got := repo.queried(); !slices.EqualFunc(got, want, slices.Equal[[]string]) {
+		t.Errorf("MySQL queries = %v, want %v", got, want)
+	}
+}
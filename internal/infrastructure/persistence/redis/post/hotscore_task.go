@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"bluebell/internal/infrastructure/metrics"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -63,6 +65,7 @@ func (r *HotScoreRefresher) Start() {
 				if err != nil {
 					batchCancel()
 					log.Printf("[HotScoreRefresher] ZRange error: %v", err)
+					metrics.HotScoreRefreshErrors.Inc()
 					break
 				}
 				if len(postIDs) == 0 {
@@ -72,6 +75,7 @@ func (r *HotScoreRefresher) Start() {
 
 				if err := r.batchRefreshGravityScores(batchCtx, postIDs); err != nil {
 					log.Printf("[HotScoreRefresher] batch refresh error: %v", err)
+					metrics.HotScoreRefreshErrors.Inc()
 				}
 
 				batchCancel() // 主动释放 context 资源不能在无限循环中用defer
@@ -84,6 +88,8 @@ func (r *HotScoreRefresher) Start() {
 			}
 
 			log.Printf("[HotScoreRefresher] completed, processed %d posts, took %v", totalProcessed, time.Since(startTime))
+			metrics.HotScoreRefreshDuration.Observe(time.Since(startTime).Seconds())
+			metrics.HotScoreRefreshedPosts.Set(float64(totalProcessed))
 
 			// ================== 2. 阻塞等待下一次触发或退出 ==================
 			select {
@@ -4,6 +4,7 @@ import (
 	"bluebell/internal/domain"
 	"bluebell/internal/domain/entity"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -23,6 +24,7 @@ const (
 	keyPostMetaPrefix           = "post:meta:"  // bluebell:post:meta:{postID} - 帖子元数据 Hash
 	keyCommunityPostTimePrefix  = "community:post:time:"
 	keyCommunityPostScorePrefix = "community:post:score:"
+	keyPostRelatedPrefix        = "post:related:" // bluebell:post:related:{postID}:{scope} - 相关帖子 ID 列表
 	// 投票相关常量
 	oneWeekInSeconds = 100 * 7 * 24 * 3600 // 增加到100周，方便压测
 	// Gravity 算法衰减因子（Reddit/Hacker News 标准值）
@@ -335,7 +337,7 @@ func CalculateGravityScore(voteUp, voteDown int64, createTime time.Time) float64
 // ========== Hash 元数据操作 ==========
 
 // DeletePost 删除帖子时清理 Redis 缓存
-// 清理范围：全局 ZSet（time/score）、社区 ZSet（time/score）、元数据 Hash、投票记录 ZSet
+// 清理范围：全局 ZSet（time/score）、社区 ZSet（time/score）、元数据 Hash、投票记录 ZSet、详情缓存
 func (c *cacheStruct) DeletePost(ctx context.Context, postID, communityID int64) error {
 	postIDStr := strconv.FormatInt(postID, 10)
 	communityIDStr := strconv.FormatInt(communityID, 10)
@@ -356,6 +358,9 @@ func (c *cacheStruct) DeletePost(ctx context.Context, postID, communityID int64)
 	// 投票记录 ZSet
 	pipeline.Del(ctx, redisKey(keyPostVotedZSetPrefix+postIDStr))
 
+	// 详情缓存：调用方在事务提交后执行，清除提交前并发读取可能回填的旧详情
+	pipeline.Del(ctx, detailKey(postIDStr))
+
 	_, err := pipeline.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("delete post cache cleanup failed (post_id: %d): %w", postID, err)
@@ -377,3 +382,40 @@ func (c *cacheStruct) GetPostCommunityID(ctx context.Context, postID int64) (int
 	return communityID, nil
 }
 
+// GetUserVote 获取用户当前对帖子的投票值（1/-1），未投票时返回 0
+func (c *cacheStruct) GetUserVote(ctx context.Context, userID, postID string) (float64, error) {
+	val, err := c.rdb.ZScore(ctx, redisKey(keyPostVotedZSetPrefix+postID), userID).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get user vote failed (post_id: %s, user_id: %s): %w", postID, userID, err)
+	}
+	return val, nil
+}
+
+// GetRelatedPostIDs 读取缓存的相关帖子 ID 列表
+// 空列表同样会被缓存，以免没有相关帖子的帖子反复穿透到 ES
+func (c *cacheStruct) GetRelatedPostIDs(ctx context.Context, postID int64, scope string) ([]string, bool, error) {
+	key := redisKey(fmt.Sprintf("%s%d:%s", keyPostRelatedPrefix, postID, scope))
+	val, err := c.rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get related posts failed (post_id: %d): %w", postID, err)
+	}
+	if val == "" {
+		return []string{}, true, nil
+	}
+	return strings.Split(val, ","), true, nil
+}
+
+// SetRelatedPostIDs 缓存相关帖子 ID 列表
+func (c *cacheStruct) SetRelatedPostIDs(ctx context.Context, postID int64, scope string, ids []string, ttl time.Duration) error {
+	key := redisKey(fmt.Sprintf("%s%d:%s", keyPostRelatedPrefix, postID, scope))
+	if err := c.rdb.Set(ctx, key, strings.Join(ids, ","), ttl).Err(); err != nil {
+		return fmt.Errorf("set related posts failed (post_id: %d): %w", postID, err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package tracing OpenTelemetry 链路追踪初始化
+//
+// 全局 TracerProvider 和 W3C Trace Context 传播器在这里设置，各组件通过 otel.Tracer 取用：
+// HTTP 由 otelgin 中间件创建服务端 Span，GORM 和 go-redis 通过各自的插件创建子 Span，
+// RabbitMQ 消息在 Header 中携带 traceparent，消费者据此延续同一条链路
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"bluebell/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.uber.org/zap"
+)
+
+// 导出方式
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// defaultServiceName 未配置服务名时使用
+const defaultServiceName = "bluebell"
+
+// Init 按配置初始化全局 TracerProvider，返回的 shutdown 在退出前调用以导出剩余 Span
+// 传播器总会被设置：即使本进程不采集，也要把上游的 trace context 原样传递下去
+func Init(ctx context.Context, cfg *config.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		zap.L().Warn("opentelemetry error", zap.Error(err))
+	}))
+
+	noop := func(context.Context) error { return nil }
+	tc := cfg.Tracing
+	if tc == nil || !tc.Enabled {
+		return noop, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, tc.Exporter, tc.Endpoint, tc.FilePath)
+	if err != nil {
+		return noop, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName(cfg)),
+		semconv.DeploymentEnvironmentName(cfg.App.Mode),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("build trace resource failed: %w", err)
+	}
+
+	ratio := tc.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// 跟随上游的采样决定，只有根 Span 按比例采样
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	zap.L().Info("tracing enabled",
+		zap.String("exporter", tc.Exporter),
+		zap.String("service", ServiceName(cfg)),
+		zap.Float64("sample_ratio", ratio))
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeOutput != nil {
+			if cerr := closeOutput.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// ServiceName 返回配置的服务名，未配置时为 bluebell
+func ServiceName(cfg *config.Config) string {
+	if cfg.Tracing != nil && cfg.Tracing.ServiceName != "" {
+		return cfg.Tracing.ServiceName
+	}
+	return defaultServiceName
+}
+
+// Enabled 是否开启了链路追踪
+func Enabled(cfg *config.Config) bool {
+	return cfg.Tracing != nil && cfg.Tracing.Enabled
+}
+
+// newExporter 创建 Span 导出器；file 导出时同时返回需要在关闭时释放的文件
+func newExporter(ctx context.Context, kind, endpoint, filePath string) (sdktrace.SpanExporter, io.Closer, error) {
+	switch kind {
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, nil, fmt.Errorf("create stdout trace exporter failed: %w", err)
+		}
+		return exp, nil, nil
+	case ExporterFile:
+		if filePath == "" {
+			return nil, nil, fmt.Errorf("tracing.file_path is required for the file exporter")
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			return nil, nil, fmt.Errorf("create trace file dir failed: %w", err)
+		}
+		f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open trace file failed: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("create file trace exporter failed: %w", err)
+		}
+		return exp, f, nil
+	case ExporterOTLP, "":
+		exp, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create otlp trace exporter failed: %w", err)
+		}
+		return exp, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", kind)
+	}
+}
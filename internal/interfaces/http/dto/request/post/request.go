@@ -20,6 +20,12 @@ type VoteRequest struct {
 	Direction int8  `json:"direction" binding:"required,oneof=1 0 -1"`
 }
 
+// RelatedPostsRequest 相关帖子查询参数
+type RelatedPostsRequest struct {
+	SameCommunity bool `form:"same_community"`                        // 只推荐同一社区的帖子
+	Size          int  `form:"size,default=5" binding:"min=1,max=20"` // 返回数量
+}
+
 // 排序规则常量
 const (
 	OrderTime  = "time"
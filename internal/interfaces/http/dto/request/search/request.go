@@ -1,8 +1,22 @@
 package searchreq
 
+import "time"
+
 // SearchRequest 搜索请求参数
 type SearchRequest struct {
-	Keyword  string `form:"keyword" binding:"required"`      // 搜索关键词
-	Page     int    `form:"page,default=1"`                // 页码，默认1
-	PageSize int    `form:"page_size,default=20"`           // 每页数量，默认20
-}
\ No newline at end of file
+	Keyword      string    `form:"keyword" binding:"required"`                               // 搜索关键词
+	Page         int       `form:"page,default=1"`                                           // 页码，默认1
+	PageSize     int       `form:"page_size,default=20"`                                     // 每页数量，默认20
+	CommunityIDs []int64   `form:"community_id"`                                             // 社区过滤，可重复传递多个
+	AuthorID     int64     `form:"author_id"`                                                // 作者过滤
+	StartDate    time.Time `form:"start_date" time_format:"2006-01-02"`                      // 起始日期（含）
+	EndDate      time.Time `form:"end_date" time_format:"2006-01-02"`                        // 结束日期（含）
+	Sort         string    `form:"sort,default=relevance" binding:"oneof=relevance new top"` // 排序方式
+	Type         string    `form:"type,default=post" binding:"oneof=post remark all"`        // 搜索范围
+}
+
+// SuggestRequest 搜索框输入联想参数
+type SuggestRequest struct {
+	Prefix string `form:"prefix" binding:"required,max=50"`       // 已输入的前缀
+	Size   int    `form:"size,default=10" binding:"min=1,max=20"` // 最多返回的标题数
+}
@@ -0,0 +1,59 @@
+package postResp
+
+import "time"
+
+// SearchResponse 搜索结果返回结构
+type SearchResponse struct {
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Posts    []*SearchDetail `json:"posts"`
+	Facets   SearchFacets    `json:"facets"`
+	// Suggestion 命中过少时的纠错建议
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// SuggestResponse 搜索框输入联想返回结构
+type SuggestResponse struct {
+	Titles      []string              `json:"titles"`
+	Communities []*SuggestedCommunity `json:"communities"`
+}
+
+// SuggestedCommunity 名称匹配输入前缀的社区
+type SuggestedCommunity struct {
+	CommunityID   int64  `json:"community_id"`
+	CommunityName string `json:"community_name"`
+}
+
+// SearchDetail 单条搜索结果，与帖子列表项结构一致并附带社区名称和高亮片段
+// 评论命中时主体为所属帖子，命中的评论放在 Remark 中
+type SearchDetail struct {
+	Type string `json:"type"` // post | remark
+	DetailResponse
+	CommunityName    string        `json:"community_name"`
+	HighlightTitle   []string      `json:"highlight_title,omitempty"`
+	HighlightContent []string      `json:"highlight_content,omitempty"`
+	Remark           *SearchRemark `json:"remark,omitempty"`
+}
+
+// SearchRemark 命中的评论
+type SearchRemark struct {
+	RemarkID   string    `json:"remark_id"`
+	Content    string    `json:"content"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	CreateTime time.Time `json:"create_time"`
+	Highlight  []string  `json:"highlight,omitempty"`
+}
+
+// SearchFacets 搜索结果分面统计
+type SearchFacets struct {
+	Communities []*CommunityFacet `json:"communities"`
+}
+
+// CommunityFacet 单个社区的搜索结果数
+type CommunityFacet struct {
+	CommunityID   int64  `json:"community_id"`
+	CommunityName string `json:"community_name"`
+	Count         int64  `json:"count"`
+}
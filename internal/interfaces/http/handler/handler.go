@@ -4,7 +4,6 @@ package handler
 
 import (
 	"bluebell/internal/application"
-	"bluebell/internal/infrastructure/mq"
 	"bluebell/internal/interfaces/http/handler/community_handler"
 	"bluebell/internal/interfaces/http/handler/post_handler"
 	"bluebell/internal/interfaces/http/handler/search_handler"
@@ -28,11 +27,10 @@ func NewProvider(
 	userService application.UserService,
 	postService application.PostService,
 	communityService application.CommunityService,
-	publisher *mq.Publisher,
 ) *Provider {
 	return &Provider{
 		UserHandler:      user_handler.New(userService),
-		PostHandler:      post_handler.New(postService, publisher),
+		PostHandler:      post_handler.New(postService),
 		CommunityHandler: community_handler.New(communityService),
 		SearchHandler:    search_handler.New(postService),
 	}
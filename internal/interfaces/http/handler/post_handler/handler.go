@@ -4,11 +4,9 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"bluebell/internal/application"
-	"bluebell/internal/infrastructure/mq"
-	"bluebell/internal/infrastructure/persistence/mysql/model"
+	"bluebell/internal/infrastructure/logger"
 	"bluebell/internal/infrastructure/translate"
 	"bluebell/internal/interfaces/http/dto/request/post"
 	"bluebell/internal/interfaces/http/dto/response/post"
@@ -22,11 +20,10 @@ import (
 
 type Handler struct {
 	postService application.PostService
-	publisher   *mq.Publisher
 }
 
-func New(postService application.PostService, publisher *mq.Publisher) *Handler {
-	return &Handler{postService: postService, publisher: publisher}
+func New(postService application.PostService) *Handler {
+	return &Handler{postService: postService}
 }
 
 
@@ -50,28 +47,11 @@ func (h *Handler) CreatePostHandler(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	postID, err := h.postService.CreatePost(ctx, p, userID.(int64))
-	if err != nil {
+	if _, err := h.postService.CreatePost(ctx, p, userID.(int64)); err != nil {
 		render.HandleError(c, err)
 		return
 	}
 
-	if h.publisher != nil {
-		syncMsg := &mq.SyncMessage{
-			PostID:      postID,
-			AuthorID:    userID.(int64),
-			CommunityID: p.CommunityID,
-			PostTitle:   p.Title,
-			Content:     p.Content,
-			Status:      model.PostStatusPublished,
-			CreatedAt:   time.Now().Format(time.RFC3339),
-			Action:      "index",
-		}
-		if err := h.publisher.PublishSearch(ctx, syncMsg); err != nil {
-			zap.L().Warn("publish search index message failed", zap.Error(err))
-		}
-	}
-
 	render.HandleSuccess(c, nil)
 }
 
@@ -103,7 +83,7 @@ func (h *Handler) GetPostListHandler(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindQuery(p); err != nil {
-		zap.L().Error("GetPostListHandler ShouldBindQuery failed", zap.Error(err))
+		logger.WithContext(c.Request.Context()).Error("GetPostListHandler ShouldBindQuery failed", zap.Error(err))
 		var errs validator.ValidationErrors
 		if errors.As(err, &errs) {
 			translatedErrs := errs.Translate(translate.Trans)
@@ -163,16 +143,6 @@ func (h *Handler) DeletePostHandler(c *gin.Context) {
 		return
 	}
 
-	if h.publisher != nil {
-		syncMsg := map[string]interface{}{
-			"post_id": strconv.FormatInt(postID, 10),
-			"action":  "delete",
-		}
-		if err := h.publisher.PublishSearch(ctx, syncMsg); err != nil {
-			zap.L().Warn("publish search sync message failed", zap.Error(err))
-		}
-	}
-
 	render.HandleSuccess(c, nil)
 }
 
@@ -240,6 +210,29 @@ func (h *Handler) PostRemarkHandler(c *gin.Context) {
 	render.HandleSuccess(c, nil)
 }
 
+// GetRelatedPostsHandler 获取相关帖子
+func (h *Handler) GetRelatedPostsHandler(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		render.HandleError(c, entity.ErrInvalidParam)
+		return
+	}
+
+	p := &postreq.RelatedPostsRequest{}
+	if err := c.ShouldBindQuery(p); err != nil {
+		render.HandleError(c, entity.ErrInvalidParam)
+		return
+	}
+
+	data, err := h.postService.GetRelatedPosts(c.Request.Context(), postID, p)
+	if err != nil {
+		render.HandleError(c, err)
+		return
+	}
+
+	render.HandleSuccess(c, data)
+}
+
 // GetPostRemarksHandler 获取帖子评论列表
 func (h *Handler) GetPostRemarksHandler(c *gin.Context) {
 	postIDStr := c.Param("id")
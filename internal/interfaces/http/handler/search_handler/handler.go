@@ -31,11 +31,28 @@ func (h *Handler) SearchHandler(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	resp, err := h.postSvc.SearchPosts(ctx, req.Keyword, req.Page, req.PageSize)
+	resp, err := h.postSvc.SearchPosts(ctx, req)
 	if err != nil {
 		render.HandleError(c, err)
 		return
 	}
 
 	render.HandleSuccess(c, resp)
-}
\ No newline at end of file
+}
+
+// SuggestHandler 处理搜索框输入联想请求
+func (h *Handler) SuggestHandler(c *gin.Context) {
+	req := &searchreq.SuggestRequest{}
+	if err := c.ShouldBindQuery(req); err != nil {
+		render.HandleError(c, entity.ErrInvalidParam)
+		return
+	}
+
+	resp, err := h.postSvc.SuggestPosts(c.Request.Context(), req)
+	if err != nil {
+		render.HandleError(c, err)
+		return
+	}
+
+	render.HandleSuccess(c, resp)
+}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/metrics"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,8 +34,8 @@ func classifyError(err error) (int, string) {
 
 // HandleError 根据领域层的错误类型，返回合适的 HTTP 状态码和原生的 JSON 响应
 func HandleError(c *gin.Context, err error) {
-	status, _ := classifyError(err)
-
+	status, category := classifyError(err)
+	metrics.HTTPErrors.WithLabelValues(category).Inc()
 
 	c.JSON(status, gin.H{"error": err.Error()})
 }
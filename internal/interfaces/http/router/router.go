@@ -3,17 +3,21 @@ package router
 import (
 	"bluebell/internal/config"
 	"bluebell/internal/domain"
+	"bluebell/internal/infrastructure/health"
+	"bluebell/internal/infrastructure/tracing"
 	"bluebell/internal/interfaces/http/handler"
 	"bluebell/internal/middleware"
 
-	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // NewRouter 初始化路由配置
@@ -23,26 +27,47 @@ func NewRouter(
 	hp *handler.Provider,
 	cfg *config.Config,
 	tokenCache domain.UserTokenCacheRepository,
+	idempotencyCache domain.IdempotencyCacheRepository,
+	checker *health.Checker,
 ) (*gin.Engine, error) {
 
 	r := gin.New()
 
-	fillInterval, err := time.ParseDuration(cfg.RateLimit.FillInterval)
-	if err != nil {
-		return nil, fmt.Errorf("parse rate limit fill interval failed: %w", err)
-	}
+	// 搜索联想随输入触发，按客户端单独限流
+	suggestLimit := middleware.ClientRateLimitMiddleware(cfg.RateLimit.SuggestFillInterval, cfg.RateLimit.SuggestCapacity)
+
+	// 配置热更新：限流器重建令牌桶，超时中间件读取最新的超时时间
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit.FillInterval, cfg.RateLimit.Capacity)
+	var requestTimeout atomic.Int64
+	requestTimeout.Store(int64(cfg.Timeout.Timeout))
+	config.Subscribe(func(old, new *config.Config) {
+		if *old.RateLimit != *new.RateLimit {
+			rateLimiter.Reset(new.RateLimit.FillInterval, new.RateLimit.Capacity)
+		}
+		requestTimeout.Store(int64(new.Timeout.Timeout))
+	})
+
+	// 幂等中间件：仅挂在会产生新数据的写接口上
+	idempotent := middleware.IdempotencyMiddleware(idempotencyCache, cfg.Idempotency.TTL, cfg.Idempotency.LockTTL, cfg.Idempotency.WaitTimeout)
+
+	// 指标采集和健康检查端点在挂载全局中间件之前注册，不受限流和超时影响，也不计入请求指标
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", gin.WrapH(health.LivenessHandler()))
+	r.GET("/readyz", gin.WrapH(checker.ReadinessHandler()))
 
-	timeout, err := time.ParseDuration(cfg.Timeout.Timeout)
-	if err != nil {
-		return nil, fmt.Errorf("parse request timeout failed: %w", err)
+	// 链路追踪：为每个请求创建服务端 Span，并从请求头延续上游的 trace context
+	if tracing.Enabled(cfg) {
+		r.Use(otelgin.Middleware(tracing.ServiceName(cfg)))
 	}
 
 	r.Use(
+		middleware.RequestIDMiddleware(),
 		middleware.GinLogger(),
+		middleware.MetricsMiddleware(),
 		middleware.GinRecovery(true),
-		middleware.Cors(), // 跨域中间件
-		middleware.RateLimitMiddleware(fillInterval, cfg.RateLimit.Capacity), // 令牌桶限流
-		middleware.TimeoutMiddleware(timeout),
+		middleware.Cors(),        // 跨域中间件
+		rateLimiter.Middleware(), // 令牌桶限流
+		middleware.TimeoutMiddleware(func() time.Duration { return time.Duration(requestTimeout.Load()) }),
 	)
 
 	// Swagger & PProf (仅在非生产环境)
@@ -67,7 +92,9 @@ func NewRouter(
 		apiV1.GET("/posts", hp.PostHandler.GetPostListHandler)
 		apiV1.GET("/post/:id", hp.PostHandler.GetPostDetailHandler)
 		apiV1.GET("/post/:id/remarks", hp.PostHandler.GetPostRemarksHandler)
+		apiV1.GET("/post/:id/related", hp.PostHandler.GetRelatedPostsHandler)
 		apiV1.GET("/search", hp.SearchHandler.SearchHandler)
+		apiV1.GET("/search/suggest", suggestLimit, hp.SearchHandler.SuggestHandler)
 	}
 
 	// 认证路由（需要 JWT 认证）
@@ -82,10 +109,10 @@ func NewRouter(
 		authGroup.POST("/logout", hp.UserHandler.LogoutHandler)
 
 		// 帖子操作（需登录）
-		authGroup.POST("/post", hp.PostHandler.CreatePostHandler)
+		authGroup.POST("/post", idempotent, hp.PostHandler.CreatePostHandler)
 		authGroup.DELETE("/post/:id", hp.PostHandler.DeletePostHandler)
-		authGroup.POST("/vote", hp.PostHandler.PostVoteHandler)
-		authGroup.POST("/remark", hp.PostHandler.PostRemarkHandler)
+		authGroup.POST("/vote", idempotent, hp.PostHandler.PostVoteHandler)
+		authGroup.POST("/remark", idempotent, hp.PostHandler.PostRemarkHandler)
 	}
 
 	// 404
@@ -5,10 +5,12 @@ import (
 	"bluebell/internal/domain"
 	"bluebell/internal/domain/entity"
 	"bluebell/internal/infrastructure/jwt"
+	"bluebell/internal/infrastructure/logger"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // JWTAuthMiddleware 基于JWT的认证中间件，包含 SSO 校验
@@ -52,8 +54,10 @@ func JWTAuthMiddleware(cfg *config.Config, tokenRepo domain.UserTokenCacheReposi
 			}
 		}
 
-		// 5. 将用户信息存入上下文
+		// 5. 将用户信息存入上下文，请求级 Logger 补充 user_id
 		c.Set("UserIDKey", userID)
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(logger.NewContext(ctx, logger.WithContext(ctx).With(zap.Int64("user_id", userID))))
 		c.Next()
 	}
 }
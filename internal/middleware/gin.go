@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"bluebell/internal/infrastructure/logger"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
@@ -29,14 +31,16 @@ func GinLogger() gin.HandlerFunc {
 			fields = append(fields, zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()))
 		}
 
-				status := c.Writer.Status()
-	
+		status := c.Writer.Status()
+
+		// 请求级 Logger 已带上 request_id、route、trace_id，登录请求还有 user_id
+		l := logger.WithContext(c.Request.Context())
 		if status >= 500 {
-			zap.L().Error("server error", fields...)
+			l.Error("server error", fields...)
 		} else if status >= 400 {
-			zap.L().Warn("client error", fields...)
+			l.Warn("client error", fields...)
 		} else {
-			zap.L().Info("http request", fields...)
+			l.Info("http request", fields...)
 		}
 	}
 }
@@ -49,7 +53,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 				// 1. 检查是否为 broken pipe
 				err, ok := rec.(error)
 				if ok && isBrokenPipeError(err) {
-					zap.L().Error("broken pipe", zap.Error(err), zap.String("path", c.Request.URL.Path))
+					logger.WithContext(c.Request.Context()).Error("broken pipe", zap.Error(err), zap.String("path", c.Request.URL.Path))
 					c.Abort()
 					return
 				}
@@ -65,7 +69,7 @@ func GinRecovery(stack bool) gin.HandlerFunc {
 					fields = append(fields, zap.Stack("stacktrace"))
 				}
 
-				zap.L().Error("[Recovery from panic]", fields...)
+				logger.WithContext(c.Request.Context()).Error("[Recovery from panic]", fields...)
 				c.AbortWithStatus(http.StatusInternalServerError)
 			}
 		}()
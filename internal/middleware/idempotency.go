@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"bluebell/internal/domain"
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderIdempotencyKey 客户端传入的幂等键请求头
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 标记当前响应是重放的首次响应
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 128
+	idempotencyPollEvery = 100 * time.Millisecond
+)
+
+// bodyCaptureWriter 在写出响应的同时保留一份响应体副本
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware 基于 Idempotency-Key 请求头的幂等中间件
+//
+// 参数:
+//   - ttl:         首次响应（状态码 + 响应体）在 Redis 中的保存时长
+//   - lockTTL:     首个请求处理中占位记录的最长存活时间，防止进程崩溃后永久占位
+//   - waitTimeout: 并发重复请求等待首个请求完成的最长时间，超时返回 409
+//
+// 行为:
+//   - 未携带请求头的请求直接放行
+//   - 首个请求正常处理，状态码 < 500 的响应被保存；5xx 会释放占位，允许重试
+//   - 重复请求重放首次响应，并附带 Idempotent-Replayed: true
+//   - 同一个幂等键携带不同的请求体返回 422
+//
+// 必须挂在 JWTAuthMiddleware 之后，幂等键按用户隔离
+func IdempotencyMiddleware(repo domain.IdempotencyCacheRepository, ttl, lockTTL, waitTimeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": entity.ErrInvalidParam.Error()})
+			return
+		}
+
+		// 读取请求体计算指纹，并回填给后续 Handler 使用
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": entity.ErrInvalidParam.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID, _ := c.Get("UserIDKey")
+		scopedKey := fmt.Sprintf("%v:%s:%s:%s", userID, c.Request.Method, c.FullPath(), key)
+		fingerprint := requestFingerprint(c.Request.Method, c.FullPath(), body)
+
+		ctx := c.Request.Context()
+		deadline := time.Now().Add(waitTimeout)
+		for {
+			acquired, err := repo.Acquire(ctx, scopedKey, fingerprint, lockTTL)
+			if err != nil {
+				// Redis 异常时降级处理：放弃幂等保护，保证主流程可用
+				logger.WithContext(ctx).Warn("idempotency acquire failed, skip idempotency check",
+					zap.String("key", scopedKey),
+					zap.Error(err))
+				c.Next()
+				return
+			}
+			if acquired {
+				processFirstRequest(c, repo, scopedKey, fingerprint, ttl)
+				return
+			}
+
+			record, err := repo.Get(ctx, scopedKey)
+			if err != nil {
+				logger.WithContext(ctx).Error("idempotency get record failed",
+					zap.String("key", scopedKey),
+					zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": entity.ErrServerBusy.Error()})
+				return
+			}
+			if record != nil {
+				if !record.MatchFingerprint(fingerprint) {
+					c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": entity.ErrIdempotencyMismatch.Error()})
+					return
+				}
+				if record.IsCompleted() {
+					replayResponse(c, record)
+					return
+				}
+			}
+			// record == nil 说明占位刚好过期或被释放，下一轮重新抢占
+
+			if time.Now().After(deadline) {
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": entity.ErrIdempotencyInFlight.Error()})
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				c.Abort()
+				return
+			case <-time.After(idempotencyPollEvery):
+			}
+		}
+	}
+}
+
+// processFirstRequest 执行首个请求并保存其响应
+func processFirstRequest(c *gin.Context, repo domain.IdempotencyCacheRepository, key, fingerprint string, ttl time.Duration) {
+	w := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+	c.Writer = w
+	c.Next()
+
+	// 请求上下文可能已因超时被取消，保存结果时使用独立的上下文
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), 2*time.Second)
+	defer cancel()
+
+	status := w.Status()
+	if status >= http.StatusInternalServerError {
+		if err := repo.Release(ctx, key); err != nil {
+			logger.WithContext(ctx).Error("idempotency release failed", zap.String("key", key), zap.Error(err))
+		}
+		return
+	}
+
+	record := &entity.IdempotencyRecord{
+		Fingerprint: fingerprint,
+		StatusCode:  status,
+		ContentType: w.Header().Get("Content-Type"),
+		Body:        w.body.Bytes(),
+	}
+	if err := repo.Complete(ctx, key, record, ttl); err != nil {
+		logger.WithContext(ctx).Error("idempotency complete failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// replayResponse 原样返回首次请求的响应
+func replayResponse(c *gin.Context, record *entity.IdempotencyRecord) {
+	c.Header(HeaderIdempotentReplayed, "true")
+	if len(record.Body) == 0 {
+		c.AbortWithStatus(record.StatusCode)
+		return
+	}
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// requestFingerprint 计算请求指纹：方法 + 路由 + 请求体的 SHA-256
+func requestFingerprint(method, route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte(route))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
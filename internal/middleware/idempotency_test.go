@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	idempotencycache "bluebell/internal/infrastructure/persistence/redis/idempotency"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyServer 挂载幂等中间件的测试路由，handler 决定每次真正执行时的响应
+func idempotencyServer(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	r := gin.New()
+	r.POST("/posts",
+		func(c *gin.Context) { c.Set("UserIDKey", int64(1)) },
+		IdempotencyMiddleware(idempotencycache.NewIdempotencyCache(rdb), time.Hour, time.Minute, 50*time.Millisecond),
+		handler,
+	)
+	return r
+}
+
+func postWithKey(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderIdempotencyKey, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotencyServer(t, func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"id": n})
+	})
+
+	first := postWithKey(r, "k1", `{"title":"a"}`)
+	second := postWithKey(r, "k1", `{"title":"a"}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("replay missing %s header", HeaderIdempotentReplayed)
+	}
+	if first.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("first response must not be marked as replayed")
+	}
+
+	// 同一个幂等键换了请求体
+	if w := postWithKey(r, "k1", `{"title":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("mismatched body status = %d, want 422", w.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyMiddleware_ConflictWhileInFlight(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	r := idempotencyServer(t, func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- postWithKey(r, "k1", `{}`) }()
+	<-entered
+
+	// 首个请求仍在处理，重复请求等待超时后返回 409
+	w := postWithKey(r, "k1", `{}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate status = %d, want 409", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("409 response missing Retry-After")
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first request status = %d, want 201", first.Code)
+	}
+	if w := postWithKey(r, "k1", `{}`); w.Code != http.StatusCreated || w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Errorf("after completion = %d replayed=%q, want replayed 201", w.Code, w.Header().Get(HeaderIdempotentReplayed))
+	}
+}
+
+func TestIdempotencyMiddleware_ReleasesOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotencyServer(t, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	if w := postWithKey(r, "k1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want 500", w.Code)
+	}
+	// 5xx 不保存响应，客户端用同一个幂等键重试时重新执行
+	w := postWithKey(r, "k1", `{}`)
+	if w.Code != http.StatusCreated || w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("retry = %d replayed=%q, want fresh 201", w.Code, w.Header().Get(HeaderIdempotentReplayed))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler calls = %d, want 2", calls.Load())
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/ratelimit"
+	"go.uber.org/zap"
+)
+
+// clientBucketSweepInterval 清理空闲令牌桶的间隔
+const clientBucketSweepInterval = time.Minute
+
+// ClientRateLimitMiddleware 按客户端 IP 独立计数的令牌桶限流中间件
+// 用于搜索建议这类随输入频繁触发的接口，单个客户端超限不影响其他客户端
+//
+// 参数:
+//   - fillInterval: 每个客户端的令牌填充间隔
+//   - capacity:     每个客户端的桶容量（最大突发请求数）
+func ClientRateLimitMiddleware(fillInterval time.Duration, capacity int64) gin.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		buckets   = make(map[string]*ratelimit.Bucket)
+		lastSweep = time.Now()
+	)
+
+	bucketFor := func(client string) *ratelimit.Bucket {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// 令牌已补满的桶与新建的桶等价，可直接丢弃，避免 map 随客户端数无限增长
+		if time.Since(lastSweep) > clientBucketSweepInterval {
+			for k, b := range buckets {
+				if b.Available() >= capacity {
+					delete(buckets, k)
+				}
+			}
+			lastSweep = time.Now()
+		}
+
+		b, ok := buckets[client]
+		if !ok {
+			b = ratelimit.NewBucket(fillInterval, capacity)
+			buckets[client] = b
+		}
+		return b
+	}
+
+	zap.L().Info("ClientRateLimitMiddleware initialized",
+		zap.Duration("fillInterval", fillInterval),
+		zap.Int64("capacity", capacity))
+
+	return func(c *gin.Context) {
+		if bucketFor(c.ClientIP()).TakeAvailable(1) < 1 {
+			metrics.RateLimitRejections.WithLabelValues("client").Inc()
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": entity.ErrRateLimitExceeded.Error(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
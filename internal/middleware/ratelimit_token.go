@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"bluebell/internal/domain/entity"
+	"bluebell/internal/infrastructure/logger"
+	"bluebell/internal/infrastructure/metrics"
 
 	"github.com/gin-gonic/gin"
 	"github.com/juju/ratelimit"
@@ -17,7 +19,12 @@ var (
 	limitedRequests int64
 )
 
-// RateLimitMiddleware 基于令牌桶的全局限流中间件
+// RateLimiter 基于令牌桶的全局限流器，配置热更新时可整体替换令牌桶
+type RateLimiter struct {
+	bucket atomic.Pointer[ratelimit.Bucket]
+}
+
+// NewRateLimiter 创建全局限流器
 //
 // 参数:
 //   - fillInterval: 令牌填充间隔（如 20ms 表示每 20ms 生成 1 个令牌）
@@ -29,25 +36,40 @@ var (
 //
 // 使用示例:
 //
-//	r.Use(middleware.RateLimitMiddleware(20*time.Millisecond, 100))
-func RateLimitMiddleware(fillInterval time.Duration, capacity int64) gin.HandlerFunc {
-	bucket := ratelimit.NewBucket(fillInterval, capacity)
+//	r.Use(middleware.NewRateLimiter(20*time.Millisecond, 100).Middleware())
+func NewRateLimiter(fillInterval time.Duration, capacity int64) *RateLimiter {
+	l := &RateLimiter{}
+	l.Reset(fillInterval, capacity)
+	return l
+}
+
+// Reset 按新参数重建令牌桶，新桶是满的，切换瞬间允许一次突发
+func (l *RateLimiter) Reset(fillInterval time.Duration, capacity int64) {
+	l.bucket.Store(ratelimit.NewBucket(fillInterval, capacity))
 
-	zap.L().Info("RateLimitMiddleware initialized",
+	zap.L().Info("RateLimiter bucket built",
 		zap.Duration("fillInterval", fillInterval),
 		zap.Int64("capacity", capacity),
 		zap.Float64("ratePerSecond", float64(time.Second)/float64(fillInterval)))
+}
+
+// Middleware 返回限流中间件，每个请求从当前的令牌桶取令牌
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return rateLimitHandler(func() *ratelimit.Bucket { return l.bucket.Load() })
+}
 
+func rateLimitHandler(current func() *ratelimit.Bucket) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		atomic.AddInt64(&totalRequests, 1)
 
 		// TakeAvailable 立即返回可获取的令牌数，不会阻塞
 		// 如果返回值 < 1 说明桶中没有足够令牌，触发限流
-		if bucket.TakeAvailable(1) < 1 {
+		if current().TakeAvailable(1) < 1 {
 			atomic.AddInt64(&limitedRequests, 1)
+			metrics.RateLimitRejections.WithLabelValues("global").Inc()
 			limited := atomic.LoadInt64(&limitedRequests)
 			total := atomic.LoadInt64(&totalRequests)
-			zap.L().Warn("Rate limit triggered",
+			logger.WithContext(c.Request.Context()).Warn("Rate limit triggered",
 				zap.Int64("limited", limited),
 				zap.Int64("total", total),
 				zap.Float64("rate", float64(limited)/float64(total)*100))
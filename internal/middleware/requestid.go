@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bluebell/internal/infrastructure/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderRequestID 请求 ID 请求头，客户端或网关传入时沿用，否则由服务端生成；响应中原样回传
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLen = 128
+)
+
+// RequestIDMiddleware 请求 ID 中间件
+// 为每个请求确定请求 ID，并在 Request 的 context 中存入携带 request_id、route、trace_id 的请求级 Logger，
+// 之后的中间件、Handler 和应用服务通过 logger.WithContext(ctx) 记录日志即可关联到同一请求
+//
+// 必须挂在链路追踪中间件之后，才能取到本次请求的 trace ID
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(HeaderRequestID, requestID)
+
+		ctx := logger.ContextWithRequestID(c.Request.Context(), requestID)
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		l := zap.L().With(zap.String("route", route))
+		ctx = logger.NewContext(ctx, l.With(logger.ContextFields(ctx)...))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度受限的可见 ASCII 字符，避免外部传入的值污染日志
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -3,17 +3,38 @@ package middleware
 import (
 	"bluebell/internal/domain/entity"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
 )
 
-func TimeoutMiddleware(timeoutDuration time.Duration) gin.HandlerFunc {
-	return timeout.New(
-		timeout.WithTimeout(timeoutDuration),
-		timeout.WithResponse(customTimeoutResponse),
-	)
+// timeoutHandler 按某个超时时间构建的超时中间件
+type timeoutHandler struct {
+	timeout time.Duration
+	handler gin.HandlerFunc
+}
+
+// TimeoutMiddleware 请求超时中间件
+// 每个请求读取 current() 返回的当前超时时间，变化后重建内部的超时中间件，配置热更新无需重启
+func TimeoutMiddleware(current func() time.Duration) gin.HandlerFunc {
+	var cached atomic.Pointer[timeoutHandler]
+	return func(c *gin.Context) {
+		d := current()
+		h := cached.Load()
+		if h == nil || h.timeout != d {
+			h = &timeoutHandler{
+				timeout: d,
+				handler: timeout.New(
+					timeout.WithTimeout(d),
+					timeout.WithResponse(customTimeoutResponse),
+				),
+			}
+			cached.Store(h)
+		}
+		h.handler(c)
+	}
 }
 
 // customTimeoutResponse 自定义超时响应